@@ -0,0 +1,117 @@
+package gobot
+
+import (
+	"fmt"
+
+	multierror "github.com/hashicorp/go-multierror"
+)
+
+// BatchMode selects how Robot.Batch handles a failing command.
+type BatchMode int
+
+const (
+	// BatchAllOrNothing stops at the first failing command and undoes the
+	// commands executed before it
+	BatchAllOrNothing BatchMode = iota
+
+	// BatchBestEffort executes every command regardless of failures
+	BatchBestEffort
+)
+
+// DeviceCommand is a command of a device executed by Robot.Batch. Undo
+// names the command reverting it, called with UndoParams when a later
+// command of an all-or-nothing batch fails. Commands without Undo stay
+// applied.
+type DeviceCommand struct {
+	Device     string
+	Command    string
+	Params     map[string]interface{}
+	Undo       string
+	UndoParams map[string]interface{}
+}
+
+// CommandResult reports the outcome of a DeviceCommand executed by
+// Robot.Batch. Executed is false for commands skipped after a failure,
+// Undone is true for commands reverted after a failure.
+type CommandResult struct {
+	DeviceCommand
+	Executed bool
+	Result   interface{}
+	Err      error
+	Undone   bool
+}
+
+// Batch executes cmds one after the other and reports the result of every
+// command. All commands are validated before the first one is executed, so
+// unknown devices or commands fail the whole batch without side effects.
+// In BatchAllOrNothing mode the first failing command stops the batch and
+// the commands executed before it are undone in reverse order. The
+// returned error combines the errors of all failed commands and undos.
+func (r *Robot) Batch(cmds []DeviceCommand, mode BatchMode) (results []CommandResult, err error) {
+	results = make([]CommandResult, len(cmds))
+	commands := make([]func(map[string]interface{}) interface{}, len(cmds))
+	undos := make([]func(map[string]interface{}) interface{}, len(cmds))
+
+	for i, cmd := range cmds {
+		results[i].DeviceCommand = cmd
+		if commands[i], err = r.batchCommand(cmd.Device, cmd.Command); err != nil {
+			return results, err
+		}
+		if cmd.Undo != "" {
+			if undos[i], err = r.batchCommand(cmd.Device, cmd.Undo); err != nil {
+				return results, err
+			}
+		}
+	}
+
+	for i, cmd := range cmds {
+		res := &results[i]
+		res.Executed = true
+		res.Result = commands[i](cmd.Params)
+		if res.Err = commandError(res.Result); res.Err == nil {
+			continue
+		}
+
+		err = multierror.Append(err, fmt.Errorf("%s.%s: %v", cmd.Device, cmd.Command, res.Err))
+		if mode == BatchAllOrNothing {
+			for j := i - 1; j >= 0; j-- {
+				if undos[j] == nil {
+					continue
+				}
+				if uerr := commandError(undos[j](cmds[j].UndoParams)); uerr != nil {
+					err = multierror.Append(err, fmt.Errorf("%s.%s: %v", cmds[j].Device, cmds[j].Undo, uerr))
+					continue
+				}
+				results[j].Undone = true
+			}
+			return
+		}
+	}
+	return
+}
+
+func (r *Robot) batchCommand(device string, command string) (func(map[string]interface{}) interface{}, error) {
+	commander, ok := r.Device(device).(Commander)
+	if !ok {
+		return nil, fmt.Errorf("unknown device %s", device)
+	}
+	f := commander.Command(command)
+	if f == nil {
+		return nil, fmt.Errorf("unknown command %s of device %s", command, device)
+	}
+	return f, nil
+}
+
+// commandError returns the error of a command result, either the result
+// itself or the non-nil "err" entry of a result map.
+func commandError(res interface{}) error {
+	switch res := res.(type) {
+	case error:
+		return res
+	case map[string]interface{}:
+		if err, ok := res["err"].(error); ok && err != nil {
+			return err
+		}
+	}
+	return nil
+}
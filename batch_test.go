@@ -0,0 +1,93 @@
+package gobot
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"gobot.io/x/gobot/gobottest"
+)
+
+func initTestBatchRobot() (*Robot, *[]string) {
+	r := newTestRobot("Robot1")
+	calls := &[]string{}
+	for _, name := range []string{"Device1", "Device2"} {
+		d := r.Device(name).(*testDriver)
+		prefix := name + "."
+		d.AddCommand("On", func(params map[string]interface{}) interface{} {
+			*calls = append(*calls, prefix+"On")
+			return nil
+		})
+		d.AddCommand("Off", func(params map[string]interface{}) interface{} {
+			*calls = append(*calls, prefix+"Off")
+			return nil
+		})
+		d.AddCommand("Fail", func(params map[string]interface{}) interface{} {
+			*calls = append(*calls, prefix+"Fail")
+			return map[string]interface{}{"err": errors.New("broken")}
+		})
+	}
+	return r, calls
+}
+
+func TestRobotBatch(t *testing.T) {
+	r, calls := initTestBatchRobot()
+	results, err := r.Batch([]DeviceCommand{
+		{Device: "Device1", Command: "On", Undo: "Off"},
+		{Device: "Device2", Command: "On"},
+	}, BatchAllOrNothing)
+
+	gobottest.Assert(t, err, nil)
+	gobottest.Assert(t, *calls, []string{"Device1.On", "Device2.On"})
+	gobottest.Assert(t, len(results), 2)
+	gobottest.Assert(t, results[0].Executed, true)
+	gobottest.Assert(t, results[1].Executed, true)
+	gobottest.Assert(t, results[1].Err, nil)
+}
+
+func TestRobotBatchValidation(t *testing.T) {
+	r, calls := initTestBatchRobot()
+	_, err := r.Batch([]DeviceCommand{
+		{Device: "Device1", Command: "On"},
+		{Device: "Missing", Command: "On"},
+	}, BatchBestEffort)
+	gobottest.Assert(t, err, errors.New("unknown device Missing"))
+
+	_, err = r.Batch([]DeviceCommand{
+		{Device: "Device1", Command: "On", Undo: "Reset"},
+	}, BatchBestEffort)
+	gobottest.Assert(t, err, errors.New("unknown command Reset of device Device1"))
+	gobottest.Assert(t, len(*calls), 0)
+}
+
+func TestRobotBatchAllOrNothing(t *testing.T) {
+	r, calls := initTestBatchRobot()
+	results, err := r.Batch([]DeviceCommand{
+		{Device: "Device1", Command: "On", Undo: "Off"},
+		{Device: "Device2", Command: "On", Undo: "Off"},
+		{Device: "Device2", Command: "Fail"},
+		{Device: "Device1", Command: "On"},
+	}, BatchAllOrNothing)
+
+	gobottest.Refute(t, err, nil)
+	gobottest.Assert(t, strings.Contains(err.Error(), "Device2.Fail: broken"), true)
+	gobottest.Assert(t, *calls, []string{"Device1.On", "Device2.On", "Device2.Fail", "Device2.Off", "Device1.Off"})
+	gobottest.Assert(t, results[0].Undone, true)
+	gobottest.Assert(t, results[1].Undone, true)
+	gobottest.Assert(t, results[2].Err, errors.New("broken"))
+	gobottest.Assert(t, results[3].Executed, false)
+}
+
+func TestRobotBatchBestEffort(t *testing.T) {
+	r, calls := initTestBatchRobot()
+	results, err := r.Batch([]DeviceCommand{
+		{Device: "Device1", Command: "Fail"},
+		{Device: "Device2", Command: "On", Undo: "Off"},
+	}, BatchBestEffort)
+
+	gobottest.Refute(t, err, nil)
+	gobottest.Assert(t, *calls, []string{"Device1.Fail", "Device2.On"})
+	gobottest.Assert(t, results[0].Err, errors.New("broken"))
+	gobottest.Assert(t, results[1].Executed, true)
+	gobottest.Assert(t, results[1].Undone, false)
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/codegangsta/cli"
+	"gobot.io/x/gobot/drivers/i2c"
+	"gobot.io/x/gobot/sysfs"
+)
+
+func Doctor() cli.Command {
+	return cli.Command{
+		Name:  "doctor",
+		Usage: "Check the I2C setup and the devices at the given addresses",
+		Flags: []cli.Flag{
+			cli.StringFlag{
+				Name:  "id-register",
+				Value: "0x00",
+				Usage: "register read to check that a device responds",
+			},
+			cli.StringFlag{
+				Name:  "ids",
+				Usage: "comma separated IDs expected in the ID register, e.g. 0x58,0x60",
+			},
+		},
+		Action: func(c *cli.Context) {
+			if len(c.Args()) < 2 {
+				fmt.Println("Usage:")
+				fmt.Println(" gobot doctor [--id-register <reg> --ids <id,...>] <bus> <address> [address...]")
+				fmt.Println(" e.g. gobot doctor --id-register 0xD0 --ids 0x58,0x60 1 0x76")
+				return
+			}
+
+			bus, err := strconv.Atoi(c.Args().First())
+			if err != nil {
+				fmt.Println("Invalid bus:", c.Args().First())
+				return
+			}
+
+			register, err := strconv.ParseUint(c.String("id-register"), 0, 8)
+			if err != nil {
+				fmt.Println("Invalid ID register:", c.String("id-register"))
+				return
+			}
+
+			ids := []uint8{}
+			if c.String("ids") != "" {
+				for _, arg := range strings.Split(c.String("ids"), ",") {
+					id, err := strconv.ParseUint(strings.TrimSpace(arg), 0, 8)
+					if err != nil {
+						fmt.Println("Invalid ID:", arg)
+						return
+					}
+					ids = append(ids, uint8(id))
+				}
+			}
+
+			devices := []i2c.DoctorDevice{}
+			for _, arg := range c.Args()[1:] {
+				address, err := strconv.ParseInt(arg, 0, 0)
+				if err != nil {
+					fmt.Println("Invalid address:", arg)
+					return
+				}
+				devices = append(devices, i2c.DoctorDevice{
+					Name:       "device",
+					Bus:        bus,
+					Address:    int(address),
+					IDRegister: uint8(register),
+					IDs:        ids,
+				})
+			}
+
+			doctor := i2c.NewDoctor(&sysfsConnector{buses: map[int]i2c.I2cDevice{}}, devices...)
+			doctor.CheckBus = func(bus int) error {
+				return sysfs.CheckI2cBus(fmt.Sprintf("/dev/i2c-%d", bus))
+			}
+			report := doctor.Run()
+			report.WriteTo(os.Stdout)
+			if !report.Passed() {
+				os.Exit(1)
+			}
+		},
+	}
+}
+
+// sysfsConnector provides the buses at /dev/i2c-<bus>.
+type sysfsConnector struct {
+	buses map[int]i2c.I2cDevice
+}
+
+func (s *sysfsConnector) GetConnection(address int, bus int) (i2c.Connection, error) {
+	if s.buses[bus] == nil {
+		device, err := sysfs.NewI2cDevice(fmt.Sprintf("/dev/i2c-%d", bus))
+		if err != nil {
+			return nil, err
+		}
+		s.buses[bus] = device
+	}
+	return i2c.NewConnection(s.buses[bus], address), nil
+}
+
+func (s *sysfsConnector) GetDefaultBus() int {
+	return 1
+}
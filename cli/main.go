@@ -16,6 +16,7 @@ func main() {
 	app.Usage = "Command Line Utility for generating new Gobot adaptors, drivers, and platforms"
 	app.Commands = []cli.Command{
 		Generate(),
+		Doctor(),
 	}
 	app.Run(os.Args)
 }
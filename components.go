@@ -0,0 +1,114 @@
+package gobot
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ClockComponent is the name the Clock of a robot is registered under.
+const ClockComponent = "clock"
+
+// Clock tells the time. Drivers timestamping their data look up the Clock
+// registered as ClockComponent, and fall back to SystemClock, so tests and
+// simulations can control the time of a robot.
+type Clock interface {
+	Now() time.Time
+}
+
+// SystemClock is the Clock returning the system time.
+type SystemClock struct{}
+
+// Now returns the current system time.
+func (SystemClock) Now() time.Time { return time.Now() }
+
+// Components is a registry of components shared between the connections,
+// devices and subsystems of a robot, such as loggers, clocks or metric sinks.
+// Components are registered by name and looked up when a robot is started,
+// instead of being passed to the constructor of every driver.
+//
+// A Components registry can have a parent, which is asked for every name
+// not registered locally. Robots use DefaultComponents as parent, so tests
+// can replace a component for all robots at once.
+type Components struct {
+	mutex  *sync.RWMutex
+	items  map[string]interface{}
+	parent *Components
+}
+
+// ComponentUser is implemented by connections and devices that resolve
+// shared components. UseComponents is called by the robot before the
+// connection or device is started.
+type ComponentUser interface {
+	UseComponents(c *Components) error
+}
+
+// DefaultComponents is the process wide registry every robot falls back to.
+var DefaultComponents = NewComponents(nil)
+
+// NewComponents returns a new, empty registry which falls back to parent
+// for unknown names. parent may be nil.
+func NewComponents(parent *Components) *Components {
+	return &Components{
+		mutex:  &sync.RWMutex{},
+		items:  make(map[string]interface{}),
+		parent: parent,
+	}
+}
+
+// Register adds a component under name, replacing any previously
+// registered component with the same name.
+func (c *Components) Register(name string, component interface{}) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.items[name] = component
+}
+
+// Unregister removes the component registered under name.
+func (c *Components) Unregister(name string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	delete(c.items, name)
+}
+
+// Lookup returns the component registered under name, searching the parent
+// registries if it is not registered locally.
+func (c *Components) Lookup(name string) (component interface{}, ok bool) {
+	c.mutex.RLock()
+	component, ok = c.items[name]
+	c.mutex.RUnlock()
+
+	if !ok && c.parent != nil {
+		return c.parent.Lookup(name)
+	}
+	return
+}
+
+// Names returns the names of all locally registered components.
+func (c *Components) Names() []string {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
+	names := []string{}
+	for name := range c.items {
+		names = append(names, name)
+	}
+	return names
+}
+
+// Clock returns the Clock registered as ClockComponent, or SystemClock when
+// none is registered. An error is returned when the component registered
+// under that name is not a Clock.
+func (c *Components) Clock() (Clock, error) {
+	component, ok := c.Lookup(ClockComponent)
+	if !ok {
+		return SystemClock{}, nil
+	}
+	clock, ok := component.(Clock)
+	if !ok {
+		return nil, fmt.Errorf("component %s is a %T, not a Clock", ClockComponent, component)
+	}
+	return clock, nil
+}
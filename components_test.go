@@ -0,0 +1,99 @@
+package gobot
+
+import (
+	"errors"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"gobot.io/x/gobot/gobottest"
+)
+
+type testComponentUser struct {
+	testDriver
+	components *Components
+	err        error
+}
+
+func (t *testComponentUser) UseComponents(c *Components) error {
+	t.components = c
+	return t.err
+}
+
+func TestComponentsRegisterLookup(t *testing.T) {
+	c := NewComponents(nil)
+	c.Register("clock", 42)
+
+	v, ok := c.Lookup("clock")
+	gobottest.Assert(t, ok, true)
+	gobottest.Assert(t, v, 42)
+
+	c.Unregister("clock")
+	_, ok = c.Lookup("clock")
+	gobottest.Assert(t, ok, false)
+}
+
+func TestComponentsParent(t *testing.T) {
+	parent := NewComponents(nil)
+	parent.Register("logger", "parent")
+	parent.Register("clock", "parent")
+
+	c := NewComponents(parent)
+	c.Register("clock", "child")
+
+	v, _ := c.Lookup("logger")
+	gobottest.Assert(t, v, "parent")
+	v, _ = c.Lookup("clock")
+	gobottest.Assert(t, v, "child")
+
+	names := c.Names()
+	sort.Strings(names)
+	gobottest.Assert(t, names, []string{"clock"})
+}
+
+type testClock struct{ now time.Time }
+
+func (c testClock) Now() time.Time { return c.now }
+
+func TestComponentsClock(t *testing.T) {
+	c := NewComponents(nil)
+	clock, err := c.Clock()
+	gobottest.Assert(t, err, nil)
+	gobottest.Assert(t, clock, Clock(SystemClock{}))
+
+	c.Register(ClockComponent, testClock{now: time.Unix(42, 0)})
+	clock, err = c.Clock()
+	gobottest.Assert(t, err, nil)
+	gobottest.Assert(t, clock.Now(), time.Unix(42, 0))
+
+	c.Register(ClockComponent, 42)
+	_, err = c.Clock()
+	gobottest.Assert(t, err, errors.New("component clock is a int, not a Clock"))
+}
+
+func TestRobotComponents(t *testing.T) {
+	DefaultComponents.Register("store", "default")
+	defer DefaultComponents.Unregister("store")
+
+	user := &testComponentUser{testDriver: testDriver{name: "user", Commander: NewCommander()}}
+	r := NewRobot("Robot99", []Device{user})
+	r.AutoRun = false
+
+	gobottest.Assert(t, r.Start(false), nil)
+	gobottest.Assert(t, user.components, r.Components())
+	v, _ := user.components.Lookup("store")
+	gobottest.Assert(t, v, "default")
+	gobottest.Assert(t, r.Stop(), nil)
+}
+
+func TestRobotComponentsError(t *testing.T) {
+	user := &testComponentUser{
+		testDriver: testDriver{name: "user", Commander: NewCommander()},
+		err:        errors.New("missing component"),
+	}
+	r := NewRobot("Robot99", []Device{user})
+
+	err := r.Start(false)
+	gobottest.Assert(t, strings.Contains(err.Error(), "missing component"), true)
+}
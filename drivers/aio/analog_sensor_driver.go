@@ -1,6 +1,7 @@
 package aio
 
 import (
+	"sync"
 	"time"
 
 	"gobot.io/x/gobot"
@@ -13,6 +14,8 @@ type AnalogSensorDriver struct {
 	halt       chan bool
 	interval   time.Duration
 	connection AnalogReader
+	mutex      *sync.Mutex
+	err        error
 	gobot.Eventer
 	gobot.Commander
 }
@@ -34,6 +37,7 @@ func NewAnalogSensorDriver(a AnalogReader, pin string, v ...time.Duration) *Anal
 		Commander:  gobot.NewCommander(),
 		interval:   10 * time.Millisecond,
 		halt:       make(chan bool),
+		mutex:      &sync.Mutex{},
 	}
 
 	if len(v) > 0 {
@@ -62,6 +66,7 @@ func (a *AnalogSensorDriver) Start() (err error) {
 		timer.Stop()
 		for {
 			newValue, err := a.Read()
+			a.setErr(err)
 			if err != nil {
 				a.Publish(a.Event(Error), err)
 			} else if newValue != value && newValue != -1 {
@@ -87,6 +92,25 @@ func (a *AnalogSensorDriver) Halt() (err error) {
 	return
 }
 
+// Health returns gobot.HealthFault with the error of the last read of the
+// sensor while it fails, and gobot.HealthOK otherwise.
+func (a *AnalogSensorDriver) Health() (gobot.HealthState, error) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	if a.err != nil {
+		return gobot.HealthFault, a.err
+	}
+	return gobot.HealthOK, nil
+}
+
+func (a *AnalogSensorDriver) setErr(err error) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	a.err = err
+}
+
 // Name returns the AnalogSensorDrivers name
 func (a *AnalogSensorDriver) Name() string { return a.name }
 
@@ -11,6 +11,7 @@ import (
 )
 
 var _ gobot.Driver = (*AnalogSensorDriver)(nil)
+var _ gobot.HealthChecker = (*AnalogSensorDriver)(nil)
 
 func TestAnalogSensorDriver(t *testing.T) {
 	a := newAioTestAdaptor()
@@ -97,6 +98,33 @@ func TestAnalogSensorDriverStart(t *testing.T) {
 	}
 }
 
+func TestAnalogSensorDriverHealth(t *testing.T) {
+	sem := make(chan bool, 1)
+	a := newAioTestAdaptor()
+	d := NewAnalogSensorDriver(a, "1", time.Millisecond)
+	state, err := d.Health()
+	gobottest.Assert(t, state, gobot.HealthOK)
+	gobottest.Assert(t, err, nil)
+
+	a.TestAdaptorAnalogRead(func() (val int, err error) {
+		return 0, errors.New("read error")
+	})
+	d.Once(Error, func(data interface{}) {
+		sem <- true
+	})
+	gobottest.Assert(t, d.Start(), nil)
+	defer d.Halt()
+
+	select {
+	case <-sem:
+	case <-time.After(100 * time.Millisecond):
+		t.Fatalf("AnalogSensorDriver Event \"Error\" was not published")
+	}
+	state, err = d.Health()
+	gobottest.Assert(t, state, gobot.HealthFault)
+	gobottest.Assert(t, err, errors.New("read error"))
+}
+
 func TestAnalogSensorDriverHalt(t *testing.T) {
 	d := NewAnalogSensorDriver(newAioTestAdaptor(), "1")
 	done := make(chan struct{})
@@ -1,6 +1,7 @@
 package gpio
 
 import (
+	"sync"
 	"time"
 
 	"gobot.io/x/gobot"
@@ -15,6 +16,8 @@ type ButtonDriver struct {
 	halt         chan bool
 	interval     time.Duration
 	connection   DigitalReader
+	mutex        *sync.Mutex
+	err          error
 	gobot.Eventer
 }
 
@@ -33,6 +36,7 @@ func NewButtonDriver(a DigitalReader, pin string, v ...time.Duration) *ButtonDri
 		Eventer:      gobot.NewEventer(),
 		interval:     10 * time.Millisecond,
 		halt:         make(chan bool),
+		mutex:        &sync.Mutex{},
 	}
 
 	if len(v) > 0 {
@@ -57,6 +61,7 @@ func (b *ButtonDriver) Start() (err error) {
 	go func() {
 		for {
 			newValue, err := b.connection.DigitalRead(b.Pin())
+			b.setErr(err)
 			if err != nil {
 				b.Publish(Error, err)
 			} else if newValue != state && newValue != -1 {
@@ -79,6 +84,25 @@ func (b *ButtonDriver) Halt() (err error) {
 	return
 }
 
+// Health returns gobot.HealthFault with the error of the last read of the
+// button while it fails, and gobot.HealthOK otherwise.
+func (b *ButtonDriver) Health() (gobot.HealthState, error) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if b.err != nil {
+		return gobot.HealthFault, b.err
+	}
+	return gobot.HealthOK, nil
+}
+
+func (b *ButtonDriver) setErr(err error) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	b.err = err
+}
+
 // Name returns the ButtonDrivers name
 func (b *ButtonDriver) Name() string { return b.name }
 
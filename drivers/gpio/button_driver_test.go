@@ -11,6 +11,7 @@ import (
 )
 
 var _ gobot.Driver = (*ButtonDriver)(nil)
+var _ gobot.HealthChecker = (*ButtonDriver)(nil)
 
 const buttonTestDelay = 250
 
@@ -147,6 +148,33 @@ func TestButtonDriverDefaultState(t *testing.T) {
 	}
 }
 
+func TestButtonDriverHealth(t *testing.T) {
+	sem := make(chan bool, 1)
+	a := newGpioTestAdaptor()
+	d := NewButtonDriver(a, "1", time.Millisecond)
+	state, err := d.Health()
+	gobottest.Assert(t, state, gobot.HealthOK)
+	gobottest.Assert(t, err, nil)
+
+	a.TestAdaptorDigitalRead(func() (val int, err error) {
+		return 0, errors.New("digital read error")
+	})
+	d.Once(Error, func(data interface{}) {
+		sem <- true
+	})
+	gobottest.Assert(t, d.Start(), nil)
+	defer d.Halt()
+
+	select {
+	case <-sem:
+	case <-time.After(buttonTestDelay * time.Millisecond):
+		t.Fatalf("ButtonDriver Event \"Error\" was not published")
+	}
+	state, err = d.Health()
+	gobottest.Assert(t, state, gobot.HealthFault)
+	gobottest.Assert(t, err, errors.New("digital read error"))
+}
+
 func TestButtonDriverDefaultName(t *testing.T) {
 	g := initTestButtonDriver()
 	gobottest.Assert(t, strings.HasPrefix(g.Name(), "Button"), true)
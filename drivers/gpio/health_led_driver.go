@@ -0,0 +1,179 @@
+package gpio
+
+import (
+	"sync"
+	"time"
+
+	"gobot.io/x/gobot"
+)
+
+// BlinkPattern is a sequence of durations a LED is alternately switched on
+// and off for, starting with on.
+type BlinkPattern []time.Duration
+
+// DefaultHealthPatterns are the blink patterns shown for the health states
+// without a pattern of their own.
+var DefaultHealthPatterns = map[gobot.HealthState]BlinkPattern{
+	// a short flash every two seconds
+	gobot.HealthOK: {50 * time.Millisecond, 1950 * time.Millisecond},
+	// a slow blinking
+	gobot.HealthWarning: {500 * time.Millisecond, 500 * time.Millisecond},
+	// a fast, continuous blinking
+	gobot.HealthFault: {100 * time.Millisecond, 100 * time.Millisecond},
+	// a double flash every two seconds
+	gobot.HealthDisconnected: {
+		100 * time.Millisecond, 100 * time.Millisecond,
+		100 * time.Millisecond, 1700 * time.Millisecond,
+	},
+}
+
+// HealthLedDriver shows the health of a device on a LED, so a technician can
+// see the state of a sensor without a laptop. Before every run of a blink
+// pattern the device is asked for its health, and the pattern of the
+// reported state is shown. Any DigitalWriter can drive the LED, including
+// the pins of I/O expanders.
+type HealthLedDriver struct {
+	name     string
+	led      *LedDriver
+	device   gobot.HealthChecker
+	ledMutex *sync.Mutex
+	patterns map[gobot.HealthState]BlinkPattern
+	halt     chan bool
+	gobot.Eventer
+}
+
+// NewHealthLedDriver returns a new HealthLedDriver showing the health of
+// device on led, with the blink pattern given for each health state.
+// States missing in patterns, or all states when patterns is nil, use the
+// pattern of DefaultHealthPatterns:
+//
+//		gpio.NewHealthLedDriver(led, sensor, map[gobot.HealthState]gpio.BlinkPattern{
+//			gobot.HealthWarning: {time.Second, time.Second},
+//		})
+//
+func NewHealthLedDriver(led *LedDriver, device gobot.HealthChecker, patterns map[gobot.HealthState]BlinkPattern) *HealthLedDriver {
+	h := &HealthLedDriver{
+		name:     gobot.DefaultName("HealthLed"),
+		led:      led,
+		device:   device,
+		ledMutex: &sync.Mutex{},
+		patterns: map[gobot.HealthState]BlinkPattern{},
+		Eventer:  gobot.NewEventer(),
+	}
+	for state, pattern := range DefaultHealthPatterns {
+		h.patterns[state] = pattern
+	}
+	for state, pattern := range patterns {
+		h.patterns[state] = pattern
+	}
+
+	h.AddEvent(Error)
+
+	return h
+}
+
+// Name returns the HealthLedDrivers name
+func (h *HealthLedDriver) Name() string { return h.name }
+
+// SetName sets the HealthLedDrivers name
+func (h *HealthLedDriver) SetName(n string) { h.name = n }
+
+// Connection returns the Connection of the LED
+func (h *HealthLedDriver) Connection() gobot.Connection { return h.led.Connection() }
+
+// Start starts showing the health of the device. Starting again restarts
+// the blinking.
+// Emits the Events:
+//	Error error - switching the LED failed
+func (h *HealthLedDriver) Start() (err error) {
+	if h.halt != nil {
+		close(h.halt)
+	}
+	halt := make(chan bool)
+	h.halt = halt
+
+	go func() {
+		for {
+			if !h.blink(h.pattern(), halt) {
+				return
+			}
+		}
+	}()
+	return
+}
+
+// Halt stops showing the health of the device and switches the LED off.
+func (h *HealthLedDriver) Halt() (err error) {
+	if h.halt != nil {
+		close(h.halt)
+		h.halt = nil
+	}
+
+	h.ledMutex.Lock()
+	defer h.ledMutex.Unlock()
+
+	return h.led.Off()
+}
+
+// pattern returns the blink pattern for the current health of the device.
+// Unknown states are shown as HealthFault.
+func (h *HealthLedDriver) pattern() BlinkPattern {
+	state, _ := h.device.Health()
+	if pattern, ok := h.patterns[state]; ok {
+		return pattern
+	}
+	return h.patterns[gobot.HealthFault]
+}
+
+// blink runs pattern once, it returns false when the driver was halted.
+// An empty pattern keeps the LED off for a second.
+func (h *HealthLedDriver) blink(pattern BlinkPattern, halt chan bool) bool {
+	if len(pattern) == 0 {
+		h.switchLed(false, halt)
+		return waitOrHalt(time.Second, halt)
+	}
+
+	for i, d := range pattern {
+		h.switchLed(i%2 == 0, halt)
+		if !waitOrHalt(d, halt) {
+			return false
+		}
+	}
+	h.switchLed(false, halt)
+	return true
+}
+
+// switchLed switches the LED on or off and publishes the error if it fails.
+// The LED is left to Halt once halt was closed.
+func (h *HealthLedDriver) switchLed(on bool, halt chan bool) {
+	h.ledMutex.Lock()
+	defer h.ledMutex.Unlock()
+
+	select {
+	case <-halt:
+		return
+	default:
+	}
+
+	var err error
+	if on {
+		err = h.led.On()
+	} else {
+		err = h.led.Off()
+	}
+	if err != nil {
+		h.Publish(Error, err)
+	}
+}
+
+// waitOrHalt waits for d, it returns false when halt was closed meanwhile.
+func waitOrHalt(d time.Duration, halt chan bool) bool {
+	timer := time.NewTimer(d)
+	select {
+	case <-timer.C:
+		return true
+	case <-halt:
+		timer.Stop()
+		return false
+	}
+}
@@ -0,0 +1,122 @@
+package gpio
+
+import (
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"gobot.io/x/gobot"
+	"gobot.io/x/gobot/gobottest"
+)
+
+var _ gobot.Driver = (*HealthLedDriver)(nil)
+
+type testHealthChecker struct {
+	mtx   sync.Mutex
+	state gobot.HealthState
+}
+
+func (t *testHealthChecker) Health() (gobot.HealthState, error) {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+	if t.state != gobot.HealthOK {
+		return t.state, errors.New("sensor disconnected")
+	}
+	return gobot.HealthOK, nil
+}
+
+func initTestHealthLedDriver(patterns map[gobot.HealthState]BlinkPattern) (*HealthLedDriver, *testHealthChecker, chan byte) {
+	a := newGpioTestAdaptor()
+	writes := make(chan byte, 100)
+	a.testAdaptorDigitalWrite = func() (err error) {
+		writes <- 1
+		return nil
+	}
+	device := &testHealthChecker{}
+	return NewHealthLedDriver(NewLedDriver(a, "1"), device, patterns), device, writes
+}
+
+func TestHealthLedDriver(t *testing.T) {
+	d, _, _ := initTestHealthLedDriver(nil)
+	gobottest.Assert(t, strings.HasPrefix(d.Name(), "HealthLed"), true)
+	d.SetName("status")
+	gobottest.Assert(t, d.Name(), "status")
+	gobottest.Refute(t, d.Connection(), nil)
+}
+
+func TestHealthLedDriverPattern(t *testing.T) {
+	warning := BlinkPattern{time.Millisecond}
+	d, device, _ := initTestHealthLedDriver(map[gobot.HealthState]BlinkPattern{
+		gobot.HealthWarning: warning,
+	})
+	gobottest.Assert(t, d.pattern(), DefaultHealthPatterns[gobot.HealthOK])
+
+	device.state = gobot.HealthWarning
+	gobottest.Assert(t, d.pattern(), warning)
+
+	device.state = gobot.HealthFault
+	gobottest.Assert(t, d.pattern(), DefaultHealthPatterns[gobot.HealthFault])
+
+	device.state = gobot.HealthDisconnected
+	gobottest.Assert(t, d.pattern(), DefaultHealthPatterns[gobot.HealthDisconnected])
+
+	// unknown states are shown as a fault
+	device.state = gobot.HealthState(9)
+	gobottest.Assert(t, d.pattern(), DefaultHealthPatterns[gobot.HealthFault])
+}
+
+func TestHealthLedDriverStartHalt(t *testing.T) {
+	d, device, writes := initTestHealthLedDriver(map[gobot.HealthState]BlinkPattern{
+		gobot.HealthDisconnected: {time.Millisecond, time.Millisecond},
+	})
+	device.state = gobot.HealthDisconnected
+
+	gobottest.Assert(t, d.Start(), nil)
+	for i := 0; i < 4; i++ {
+		select {
+		case <-writes:
+		case <-time.After(time.Second):
+			t.Fatalf("LED was not blinking")
+		}
+	}
+	gobottest.Assert(t, d.Halt(), nil)
+	gobottest.Assert(t, d.led.State(), false)
+}
+
+func TestHealthLedDriverRestartHalt(t *testing.T) {
+	d, _, _ := initTestHealthLedDriver(map[gobot.HealthState]BlinkPattern{
+		gobot.HealthOK: {time.Millisecond, time.Millisecond},
+	})
+
+	gobottest.Assert(t, d.Start(), nil)
+	gobottest.Assert(t, d.Start(), nil)
+	gobottest.Assert(t, d.Halt(), nil)
+	gobottest.Assert(t, d.Halt(), nil)
+}
+
+func TestHealthLedDriverError(t *testing.T) {
+	a := newGpioTestAdaptor()
+	a.testAdaptorDigitalWrite = func() (err error) {
+		return errors.New("write error")
+	}
+	d := NewHealthLedDriver(NewLedDriver(a, "1"), &testHealthChecker{}, map[gobot.HealthState]BlinkPattern{
+		gobot.HealthOK: {time.Millisecond, time.Millisecond},
+	})
+
+	sem := make(chan interface{}, 1)
+	d.Once(Error, func(data interface{}) {
+		sem <- data
+	})
+
+	gobottest.Assert(t, d.Start(), nil)
+	defer d.Halt()
+
+	select {
+	case err := <-sem:
+		gobottest.Assert(t, err, errors.New("write error"))
+	case <-time.After(time.Second):
+		t.Errorf("Error was not published")
+	}
+}
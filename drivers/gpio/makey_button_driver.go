@@ -1,6 +1,7 @@
 package gpio
 
 import (
+	"sync"
 	"time"
 
 	"gobot.io/x/gobot"
@@ -14,6 +15,8 @@ type MakeyButtonDriver struct {
 	connection DigitalReader
 	Active     bool
 	interval   time.Duration
+	mutex      *sync.Mutex
+	err        error
 	gobot.Eventer
 }
 
@@ -31,6 +34,7 @@ func NewMakeyButtonDriver(a DigitalReader, pin string, v ...time.Duration) *Make
 		Eventer:    gobot.NewEventer(),
 		interval:   10 * time.Millisecond,
 		halt:       make(chan bool),
+		mutex:      &sync.Mutex{},
 	}
 
 	if len(v) > 0 {
@@ -44,6 +48,25 @@ func NewMakeyButtonDriver(a DigitalReader, pin string, v ...time.Duration) *Make
 	return m
 }
 
+// Health returns gobot.HealthFault with the error of the last read of the
+// button while it fails, and gobot.HealthOK otherwise.
+func (b *MakeyButtonDriver) Health() (gobot.HealthState, error) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if b.err != nil {
+		return gobot.HealthFault, b.err
+	}
+	return gobot.HealthOK, nil
+}
+
+func (b *MakeyButtonDriver) setErr(err error) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	b.err = err
+}
+
 // Name returns the MakeyButtonDrivers name
 func (b *MakeyButtonDriver) Name() string { return b.name }
 
@@ -69,6 +92,7 @@ func (b *MakeyButtonDriver) Start() (err error) {
 		timer.Stop()
 		for {
 			newValue, err := b.connection.DigitalRead(b.Pin())
+			b.setErr(err)
 			if err != nil {
 				b.Publish(Error, err)
 			} else if newValue != state && newValue != -1 {
@@ -10,6 +10,7 @@ import (
 )
 
 var _ gobot.Driver = (*MakeyButtonDriver)(nil)
+var _ gobot.HealthChecker = (*MakeyButtonDriver)(nil)
 
 const makeyTestDelay = 250
 
@@ -42,6 +43,33 @@ func TestMakeyButtonDriver(t *testing.T) {
 	gobottest.Assert(t, d.interval, 30*time.Second)
 }
 
+func TestMakeyButtonDriverHealth(t *testing.T) {
+	sem := make(chan bool, 1)
+	a := newGpioTestAdaptor()
+	d := NewMakeyButtonDriver(a, "1", time.Millisecond)
+	state, err := d.Health()
+	gobottest.Assert(t, state, gobot.HealthOK)
+	gobottest.Assert(t, err, nil)
+
+	a.TestAdaptorDigitalRead(func() (val int, err error) {
+		return 0, errors.New("digital read error")
+	})
+	d.Once(Error, func(data interface{}) {
+		sem <- true
+	})
+	gobottest.Assert(t, d.Start(), nil)
+	defer d.Halt()
+
+	select {
+	case <-sem:
+	case <-time.After(makeyTestDelay * time.Millisecond):
+		t.Fatalf("MakeyButtonDriver Event \"Error\" was not published")
+	}
+	state, err = d.Health()
+	gobottest.Assert(t, state, gobot.HealthFault)
+	gobottest.Assert(t, err, errors.New("digital read error"))
+}
+
 func TestMakeyButtonDriverStart(t *testing.T) {
 	sem := make(chan bool)
 	a := newGpioTestAdaptor()
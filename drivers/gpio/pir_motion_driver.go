@@ -1,6 +1,7 @@
 package gpio
 
 import (
+	"sync"
 	"time"
 
 	"gobot.io/x/gobot"
@@ -14,6 +15,8 @@ type PIRMotionDriver struct {
 	halt       chan bool
 	interval   time.Duration
 	connection DigitalReader
+	mutex      *sync.Mutex
+	err        error
 	gobot.Eventer
 }
 
@@ -31,6 +34,7 @@ func NewPIRMotionDriver(a DigitalReader, pin string, v ...time.Duration) *PIRMot
 		Eventer:    gobot.NewEventer(),
 		interval:   10 * time.Millisecond,
 		halt:       make(chan bool),
+		mutex:      &sync.Mutex{},
 	}
 
 	if len(v) > 0 {
@@ -59,6 +63,9 @@ func (p *PIRMotionDriver) Start() (err error) {
 	go func() {
 		for {
 			newValue, err := p.connection.DigitalRead(p.Pin())
+			p.mutex.Lock()
+			p.err = err
+			p.mutex.Unlock()
 			if err != nil {
 				p.Publish(Error, err)
 			}
@@ -91,6 +98,18 @@ func (p *PIRMotionDriver) Halt() (err error) {
 	return
 }
 
+// Health returns gobot.HealthFault with the error of the last read of the
+// sensor while it fails, and gobot.HealthOK otherwise.
+func (p *PIRMotionDriver) Health() (gobot.HealthState, error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if p.err != nil {
+		return gobot.HealthFault, p.err
+	}
+	return gobot.HealthOK, nil
+}
+
 // Name returns the PIRMotionDriver name
 func (p *PIRMotionDriver) Name() string { return p.name }
 
@@ -11,6 +11,7 @@ import (
 )
 
 var _ gobot.Driver = (*PIRMotionDriver)(nil)
+var _ gobot.HealthChecker = (*PIRMotionDriver)(nil)
 
 const motionTestDelay = 150
 
@@ -89,6 +90,33 @@ func TestPIRMotionDriverStart(t *testing.T) {
 	}
 }
 
+func TestPIRMotionDriverHealth(t *testing.T) {
+	sem := make(chan bool, 1)
+	a := newGpioTestAdaptor()
+	d := NewPIRMotionDriver(a, "1", time.Millisecond)
+	state, err := d.Health()
+	gobottest.Assert(t, state, gobot.HealthOK)
+	gobottest.Assert(t, err, nil)
+
+	a.TestAdaptorDigitalRead(func() (val int, err error) {
+		return 0, errors.New("digital read error")
+	})
+	d.Once(Error, func(data interface{}) {
+		sem <- true
+	})
+	gobottest.Assert(t, d.Start(), nil)
+	defer d.Halt()
+
+	select {
+	case <-sem:
+	case <-time.After(motionTestDelay * time.Millisecond):
+		t.Fatalf("PIRMotionDriver Event \"Error\" was not published")
+	}
+	state, err = d.Health()
+	gobottest.Assert(t, state, gobot.HealthFault)
+	gobottest.Assert(t, err, errors.New("digital read error"))
+}
+
 func TestPIRDriverDefaultName(t *testing.T) {
 	d := initTestPIRMotionDriver()
 	gobottest.Assert(t, strings.HasPrefix(d.Name(), "PIR"), true)
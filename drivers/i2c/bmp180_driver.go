@@ -3,6 +3,8 @@ package i2c
 import (
 	"bytes"
 	"encoding/binary"
+	"io"
+	"sync"
 	"time"
 
 	"gobot.io/x/gobot"
@@ -55,6 +57,11 @@ type BMP180Driver struct {
 	connection Connection
 	Config
 	calibrationCoefficients *calibrationCoefficients
+
+	mutex           *sync.Mutex
+	rawTempRead     int16
+	rawPressureRead int32
+	recentErrors    errorChain
 }
 
 // NewBMP180Driver creates a new driver with the i2c interface for the BMP180 device.
@@ -72,6 +79,7 @@ func NewBMP180Driver(c Connector, options ...func(Config)) *BMP180Driver {
 		Mode:                    BMP180UltraLowPower,
 		Config:                  NewConfig(),
 		calibrationCoefficients: &calibrationCoefficients{},
+		mutex:                   &sync.Mutex{},
 	}
 
 	for _, option := range options {
@@ -115,6 +123,7 @@ func (d *BMP180Driver) initialization() (err error) {
 	var coefficients []byte
 	// read the 11 calibration coefficients.
 	if coefficients, err = d.read(bmp180RegisterAC1MSB, 22); err != nil {
+		d.recordError("read calibration coefficients", err)
 		return err
 	}
 	buf := bytes.NewBuffer(coefficients)
@@ -162,16 +171,22 @@ func (d *BMP180Driver) Pressure() (pressure float32, err error) {
 
 func (d *BMP180Driver) rawTemp() (int16, error) {
 	if _, err := d.connection.Write([]byte{bmp180RegisterCtl, bmp180CmdTemp}); err != nil {
+		d.recordError("start temperature measurement", err)
 		return 0, err
 	}
 	time.Sleep(5 * time.Millisecond)
 	ret, err := d.read(bmp180RegisterTempMSB, 2)
 	if err != nil {
+		d.recordError("read temperature", err)
 		return 0, err
 	}
 	buf := bytes.NewBuffer(ret)
 	var rawTemp int16
 	binary.Read(buf, binary.BigEndian, &rawTemp)
+
+	d.mutex.Lock()
+	d.rawTempRead = rawTemp
+	d.mutex.Unlock()
 	return rawTemp, nil
 }
 
@@ -201,17 +216,54 @@ func (d *BMP180Driver) calculateB5(rawTemp int16) int32 {
 
 func (d *BMP180Driver) rawPressure(mode BMP180OversamplingMode) (rawPressure int32, err error) {
 	if _, err = d.connection.Write([]byte{bmp180RegisterCtl, bmp180CmdPressure + byte(mode<<6)}); err != nil {
+		d.recordError("start pressure measurement", err)
 		return 0, err
 	}
 	time.Sleep(pauseForReading(mode))
 	var ret []byte
 	if ret, err = d.read(bmp180RegisterPressureMSB, 3); err != nil {
+		d.recordError("read pressure", err)
 		return 0, err
 	}
 	rawPressure = (int32(ret[0])<<16 + int32(ret[1])<<8 + int32(ret[2])) >> (8 - uint(mode))
+
+	d.mutex.Lock()
+	d.rawPressureRead = rawPressure
+	d.mutex.Unlock()
 	return rawPressure, nil
 }
 
+func (d *BMP180Driver) recordError(op string, err error) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	d.recentErrors.add(op, err)
+}
+
+// DumpState writes the calibration coefficients, the last raw readings and
+// the most recent errors of the driver to w, followed by the state of its
+// connection when it was obtained through a DiagnosticConnector.
+func (d *BMP180Driver) DumpState(w io.Writer) error {
+	d.mutex.Lock()
+	c := d.calibrationCoefficients
+	p := &errWriter{w: w}
+	p.printf("BMP180 %s mode=%d\n", d.name, d.Mode)
+	p.printf("  calibration: ac1=%d ac2=%d ac3=%d ac4=%d ac5=%d ac6=%d b1=%d b2=%d mb=%d mc=%d md=%d\n",
+		c.ac1, c.ac2, c.ac3, c.ac4, c.ac5, c.ac6, c.b1, c.b2, c.mb, c.mc, c.md)
+	p.printf("  last raw temperature: %d\n", d.rawTempRead)
+	p.printf("  last raw pressure: %d\n", d.rawPressureRead)
+	d.recentErrors.dump(p)
+	d.mutex.Unlock()
+
+	if p.err != nil {
+		return p.err
+	}
+	if dumper, ok := d.connection.(StateDumper); ok {
+		return dumper.DumpState(w)
+	}
+	return nil
+}
+
 func (d *BMP180Driver) calculatePressure(rawTemp int16, rawPressure int32, mode BMP180OversamplingMode) float32 {
 	b5 := d.calculateB5(rawTemp)
 	b6 := b5 - 4000
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"encoding/binary"
 	"errors"
+	"strings"
 	"testing"
 	"time"
 
@@ -12,6 +13,7 @@ import (
 )
 
 var _ gobot.Driver = (*BMP180Driver)(nil)
+var _ StateDumper = (*BMP180Driver)(nil)
 
 // --------- HELPERS
 func initTestBMP180Driver() (driver *BMP180Driver) {
@@ -175,6 +177,32 @@ func TestBMP180DriverPressureWriteError(t *testing.T) {
 	gobottest.Assert(t, err, errors.New("write error"))
 }
 
+func TestBMP180DriverDumpState(t *testing.T) {
+	adaptor := newI2cTestAdaptor()
+	bmp180 := NewBMP180Driver(NewDiagnosticConnector(adaptor))
+	adaptor.i2cReadImpl = func(b []byte) (int, error) {
+		buf := new(bytes.Buffer)
+		if adaptor.written[len(adaptor.written)-1] == bmp180RegisterAC1MSB {
+			binary.Write(buf, binary.BigEndian, int16(408))
+		} else {
+			return 0, errors.New("temp error")
+		}
+		copy(b, buf.Bytes())
+		return len(b), nil
+	}
+	bmp180.Start()
+	bmp180.Temperature()
+
+	var out bytes.Buffer
+	gobottest.Assert(t, bmp180.DumpState(&out), nil)
+	gobottest.Assert(t, strings.Contains(out.String(), "calibration: ac1=408 "), true)
+	gobottest.Assert(t, strings.Contains(out.String(), "last error: temp error"), true)
+	gobottest.Assert(t, strings.Contains(out.String(), "read temperature: temp error"), true)
+	// the state of the diagnostic connection follows
+	gobottest.Assert(t, strings.Contains(out.String(), "address=0x77"), true)
+	gobottest.Assert(t, strings.Contains(out.String(), "errors: 1"), true)
+}
+
 func TestBMP180DriverSetName(t *testing.T) {
 	b := initTestBMP180Driver()
 	b.SetName("TESTME")
@@ -0,0 +1,148 @@
+package i2c
+
+import (
+	"fmt"
+	"strings"
+
+	"gobot.io/x/gobot"
+)
+
+// Capability is a set of I2C operations a Connection supports.
+type Capability uint32
+
+const (
+	// CapabilityReadByte supports ReadByte
+	CapabilityReadByte Capability = 1 << iota
+	// CapabilityWriteByte supports WriteByte
+	CapabilityWriteByte
+	// CapabilityReadByteData supports ReadByteData
+	CapabilityReadByteData
+	// CapabilityWriteByteData supports WriteByteData
+	CapabilityWriteByteData
+	// CapabilityReadWordData supports ReadWordData
+	CapabilityReadWordData
+	// CapabilityWriteWordData supports WriteWordData
+	CapabilityWriteWordData
+	// CapabilityWriteBlockData supports WriteBlockData
+	CapabilityWriteBlockData
+	// CapabilityRepeatedStart supports combined transactions using a repeated start
+	CapabilityRepeatedStart
+	// CapabilityPEC supports SMBus packet error checking
+	CapabilityPEC
+
+	// AllCapabilities is reported by connections supporting every operation
+	AllCapabilities = CapabilityReadByte | CapabilityWriteByte |
+		CapabilityReadByteData | CapabilityWriteByteData |
+		CapabilityReadWordData | CapabilityWriteWordData |
+		CapabilityWriteBlockData | CapabilityRepeatedStart | CapabilityPEC
+)
+
+var capabilityNames = []string{
+	"ReadByte",
+	"WriteByte",
+	"ReadByteData",
+	"WriteByteData",
+	"ReadWordData",
+	"WriteWordData",
+	"WriteBlockData",
+	"RepeatedStart",
+	"PEC",
+}
+
+// String returns the names of the capabilities in c, separated by commas.
+func (c Capability) String() string {
+	names := []string{}
+	for i, name := range capabilityNames {
+		if c&(1<<uint(i)) != 0 {
+			names = append(names, name)
+		}
+	}
+	return strings.Join(names, ", ")
+}
+
+// CapabilityReporter is implemented by Connections able to tell which
+// operations they support. Connections not implementing it are assumed
+// to support every operation.
+type CapabilityReporter interface {
+	Capabilities() Capability
+}
+
+// capabilitiesOf returns the capabilities connection reports, or
+// AllCapabilities when it does not report them.
+func capabilitiesOf(connection Connection) Capability {
+	if reporter, ok := connection.(CapabilityReporter); ok {
+		return reporter.Capabilities()
+	}
+	return AllCapabilities
+}
+
+// CapabilityError is returned by CheckCapabilities when a connection lacks
+// operations a driver requires.
+type CapabilityError struct {
+	Adaptor string
+	Driver  string
+	Missing Capability
+}
+
+func (e *CapabilityError) Error() string {
+	return fmt.Sprintf("adaptor %s lacks %s required by %s", e.Adaptor, e.Missing, e.Driver)
+}
+
+// CheckCapabilities returns a *CapabilityError when connection reports it
+// lacks any of the capabilities in required. Drivers call it in Start, right
+// after getting their connection, so an unsuitable adaptor fails early with
+// a precise message instead of later with cryptic read errors. Wrapping
+// connections like DiagnosticConnection forward the capabilities of the
+// wrapped connection.
+func CheckCapabilities(connector Connector, connection Connection, driver string, required Capability) error {
+	missing := required &^ capabilitiesOf(connection)
+	if missing == 0 {
+		return nil
+	}
+
+	adaptor := fmt.Sprintf("%T", connector)
+	if c, ok := connector.(gobot.Connection); ok {
+		adaptor = c.Name()
+	}
+	return &CapabilityError{Adaptor: adaptor, Driver: driver, Missing: missing}
+}
+
+// functionalityReporter is implemented by buses reporting the adapter
+// functionality mask of the Linux kernel, like the sysfs I2C device.
+type functionalityReporter interface {
+	Functionality() uint64
+}
+
+// From /usr/include/linux/i2c.h, the functionality bits of the capabilities.
+var linuxFunctionality = []struct {
+	mask       uint64
+	capability Capability
+}{
+	{0x00000001, CapabilityWriteBlockData | CapabilityRepeatedStart}, // I2C_FUNC_I2C
+	{0x00000008, CapabilityPEC},                                      // I2C_FUNC_SMBUS_PEC
+	{0x00020000, CapabilityReadByte},                                 // I2C_FUNC_SMBUS_READ_BYTE
+	{0x00040000, CapabilityWriteByte},                                // I2C_FUNC_SMBUS_WRITE_BYTE
+	{0x00080000, CapabilityReadByteData},                             // I2C_FUNC_SMBUS_READ_BYTE_DATA
+	{0x00100000, CapabilityWriteByteData},                            // I2C_FUNC_SMBUS_WRITE_BYTE_DATA
+	{0x00200000, CapabilityReadWordData},                             // I2C_FUNC_SMBUS_READ_WORD_DATA
+	{0x00400000, CapabilityWriteWordData},                            // I2C_FUNC_SMBUS_WRITE_WORD_DATA
+}
+
+// Capabilities returns the operations supported by the bus of the
+// connection. Buses not reporting their functionality are assumed to
+// support every operation.
+func (c *i2cConnection) Capabilities() Capability {
+	bus, ok := c.bus.(functionalityReporter)
+	if !ok {
+		return AllCapabilities
+	}
+
+	funcs := bus.Functionality()
+	var caps Capability
+	for _, f := range linuxFunctionality {
+		if funcs&f.mask != 0 {
+			caps |= f.capability
+		}
+	}
+	return caps
+}
@@ -0,0 +1,84 @@
+package i2c
+
+import (
+	"testing"
+
+	"gobot.io/x/gobot/gobottest"
+)
+
+var _ CapabilityReporter = (*i2cConnection)(nil)
+
+type i2cCapabilityConnection struct {
+	*i2cTestAdaptor
+	capabilities Capability
+}
+
+func (c *i2cCapabilityConnection) Capabilities() Capability { return c.capabilities }
+
+type i2cFunctionalityBus struct {
+	I2cDevice
+	funcs uint64
+}
+
+func (b *i2cFunctionalityBus) Functionality() uint64 { return b.funcs }
+
+func TestCapabilityString(t *testing.T) {
+	gobottest.Assert(t, Capability(0).String(), "")
+	gobottest.Assert(t, CapabilityReadWordData.String(), "ReadWordData")
+	gobottest.Assert(t, (CapabilityReadByte | CapabilityPEC).String(), "ReadByte, PEC")
+}
+
+func TestCheckCapabilities(t *testing.T) {
+	a := newI2cTestAdaptor()
+
+	// connections not reporting capabilities are assumed to support everything
+	gobottest.Assert(t, CheckCapabilities(a, a, "Sensor", AllCapabilities), nil)
+
+	c := &i2cCapabilityConnection{i2cTestAdaptor: a, capabilities: CapabilityReadByteData}
+	gobottest.Assert(t, CheckCapabilities(a, c, "Sensor", CapabilityReadByteData), nil)
+
+	err := CheckCapabilities(a, c, "Sensor", CapabilityReadByteData|CapabilityReadWordData)
+	gobottest.Assert(t, err, &CapabilityError{Adaptor: a.Name(), Driver: "Sensor", Missing: CapabilityReadWordData})
+	gobottest.Assert(t, err.Error(), "adaptor "+a.Name()+" lacks ReadWordData required by Sensor")
+}
+
+func TestCheckCapabilitiesWrapped(t *testing.T) {
+	a := newI2cTestAdaptor()
+	c := &i2cCapabilityConnection{i2cTestAdaptor: a, capabilities: CapabilityReadByteData}
+
+	d := NewDiagnosticConnection(c, 0x10, 0)
+	gobottest.Assert(t, d.Capabilities(), CapabilityReadByteData)
+	err := CheckCapabilities(a, d, "Sensor", CapabilityReadWordData)
+	gobottest.Assert(t, err, &CapabilityError{Adaptor: a.Name(), Driver: "Sensor", Missing: CapabilityReadWordData})
+
+	f := NewFaultConnection(d, NewFaultInjector(1))
+	gobottest.Assert(t, f.Capabilities(), CapabilityReadByteData)
+	err = CheckCapabilities(a, f, "Sensor", CapabilityReadWordData)
+	gobottest.Assert(t, err, &CapabilityError{Adaptor: a.Name(), Driver: "Sensor", Missing: CapabilityReadWordData})
+
+	// wrapped connections not reporting capabilities support everything
+	gobottest.Assert(t, NewDiagnosticConnection(a, 0x10, 0).Capabilities(), AllCapabilities)
+	gobottest.Assert(t, NewFaultConnection(a, NewFaultInjector(1)).Capabilities(), AllCapabilities)
+}
+
+func TestI2CConnectionCapabilities(t *testing.T) {
+	c := NewConnection(&i2cFunctionalityBus{}, 0x10)
+	gobottest.Assert(t, c.Capabilities(), Capability(0))
+
+	c = NewConnection(&i2cFunctionalityBus{funcs: 0x00200000 | 0x00000001}, 0x10)
+	gobottest.Assert(t, c.Capabilities(), CapabilityReadWordData|CapabilityWriteBlockData|CapabilityRepeatedStart)
+
+	c = NewConnection(initI2CDevice(), 0x10)
+	gobottest.Assert(t, c.Capabilities()&CapabilityReadWordData, CapabilityReadWordData)
+	gobottest.Assert(t, c.Capabilities()&CapabilityPEC, Capability(0))
+}
+
+// i2cCapabilityConnector hands out connections with limited capabilities.
+type i2cCapabilityConnector struct {
+	*i2cTestAdaptor
+	capabilities Capability
+}
+
+func (c *i2cCapabilityConnector) GetConnection(address int, bus int) (Connection, error) {
+	return &i2cCapabilityConnection{i2cTestAdaptor: c.i2cTestAdaptor, capabilities: c.capabilities}, nil
+}
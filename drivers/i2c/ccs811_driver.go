@@ -166,6 +166,9 @@ func (d *CCS811Driver) Start() (err error) {
 	if d.connection, err = d.connector.GetConnection(address, bus); err != nil {
 		return err
 	}
+	if err = CheckCapabilities(d.connector, d.connection, d.name, CapabilityReadByteData|CapabilityWriteByteData|CapabilityReadWordData|CapabilityWriteBlockData); err != nil {
+		return err
+	}
 
 	return d.initialize()
 }
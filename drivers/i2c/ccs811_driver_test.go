@@ -47,6 +47,13 @@ func TestCCS811Connection(t *testing.T) {
 
 // // --------- CONFIG OVERIDE TESTS
 
+func TestCCS811DriverStartCapabilityError(t *testing.T) {
+	a := newI2cTestAdaptor()
+	d := NewCCS811Driver(&i2cCapabilityConnector{i2cTestAdaptor: a, capabilities: CapabilityReadByteData | CapabilityWriteByteData | CapabilityReadWordData})
+	err := d.Start()
+	gobottest.Assert(t, err, &CapabilityError{Adaptor: a.Name(), Driver: d.Name(), Missing: CapabilityWriteBlockData})
+}
+
 func TestCCS811DriverWithBus(t *testing.T) {
 	// Can it update the bus
 	d := NewCCS811Driver(newI2cTestAdaptor(), WithBus(2))
@@ -0,0 +1,112 @@
+package i2c
+
+import (
+	"math"
+	"sync"
+	"time"
+
+	"gobot.io/x/gobot"
+)
+
+// DeadbandDriver republishes the samples of a sensor by exception. A sample
+// is only published when it differs from the last published one by more
+// than the deadband, or when it is the first sample after the heartbeat
+// interval elapsed since the last publication. Publishers like MQTT
+// subscribe to the DeadbandDriver instead of the sensor, which drastically
+// reduces the traffic of slowly changing values while real changes are
+// still published immediately.
+type DeadbandDriver struct {
+	name      string
+	source    gobot.Eventer
+	event     string
+	deadband  float64
+	heartbeat time.Duration
+	mutex     *sync.Mutex
+	last      float64
+	published time.Time
+	halt      chan bool
+	gobot.Eventer
+}
+
+// NewDeadbandDriver returns a new DeadbandDriver publishing the data of the
+// event published by source, when it changes by more than deadband. A
+// heartbeat of zero disables the periodic publication of unchanged values.
+func NewDeadbandDriver(source gobot.Eventer, event string, deadband float64, heartbeat time.Duration) *DeadbandDriver {
+	d := &DeadbandDriver{
+		name:      gobot.DefaultName("Deadband"),
+		source:    source,
+		event:     event,
+		deadband:  deadband,
+		heartbeat: heartbeat,
+		mutex:     &sync.Mutex{},
+		Eventer:   gobot.NewEventer(),
+	}
+
+	d.AddEvent(Data)
+
+	return d
+}
+
+// Name returns the DeadbandDrivers name
+func (d *DeadbandDriver) Name() string { return d.name }
+
+// SetName sets the DeadbandDrivers name
+func (d *DeadbandDriver) SetName(n string) { d.name = n }
+
+// Connection returns nil, a deadband filter has no connection
+func (d *DeadbandDriver) Connection() gobot.Connection { return nil }
+
+// Start starts filtering the samples of the source. The first sample is
+// always published. Non-numeric samples are passed on unchanged.
+// Emits the Events:
+//	Data interface{} - a sample of the source
+func (d *DeadbandDriver) Start() (err error) {
+	halt := make(chan bool)
+	d.halt = halt
+
+	out := d.source.Subscribe()
+	go func() {
+		for {
+			select {
+			case evt := <-out:
+				if evt.Name == d.event && d.report(evt.Data, time.Now()) {
+					d.Publish(d.Event(Data), evt.Data)
+				}
+			case <-halt:
+				gobot.UnsubscribeAndDrain(d.source, out)
+				return
+			}
+		}
+	}()
+	return
+}
+
+// Halt stops filtering.
+func (d *DeadbandDriver) Halt() (err error) {
+	if d.halt != nil {
+		close(d.halt)
+		d.halt = nil
+	}
+	return
+}
+
+// report returns true if the sample received at now has to be published.
+func (d *DeadbandDriver) report(data interface{}, now time.Time) bool {
+	val, ok := gobot.Facts{"val": data}.Float("val")
+	if !ok {
+		return true
+	}
+
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	if !d.published.IsZero() &&
+		math.Abs(val-d.last) <= d.deadband &&
+		(d.heartbeat == 0 || now.Sub(d.published) < d.heartbeat) {
+		return false
+	}
+
+	d.last = val
+	d.published = now
+	return true
+}
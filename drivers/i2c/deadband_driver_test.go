@@ -0,0 +1,66 @@
+package i2c
+
+import (
+	"testing"
+	"time"
+
+	"gobot.io/x/gobot"
+	"gobot.io/x/gobot/gobottest"
+)
+
+var _ gobot.Driver = (*DeadbandDriver)(nil)
+
+func TestNewDeadbandDriver(t *testing.T) {
+	d := NewDeadbandDriver(gobot.NewEventer(), "data", 0.5, time.Minute)
+	gobottest.Assert(t, d.Name()[:8], "Deadband")
+	gobottest.Assert(t, d.Connection(), nil)
+	d.SetName("ambient")
+	gobottest.Assert(t, d.Name(), "ambient")
+}
+
+func TestDeadbandDriverReport(t *testing.T) {
+	d := NewDeadbandDriver(gobot.NewEventer(), "data", 0.5, time.Minute)
+	now := time.Now()
+
+	gobottest.Assert(t, d.report(20.0, now), true)
+	gobottest.Assert(t, d.report(20.3, now.Add(time.Second)), false)
+	gobottest.Assert(t, d.report(19.6, now.Add(2*time.Second)), false)
+	gobottest.Assert(t, d.report(20.6, now.Add(3*time.Second)), true)
+	gobottest.Assert(t, d.report(20.6, now.Add(30*time.Second)), false)
+	gobottest.Assert(t, d.report(20.6, now.Add(63*time.Second)), true)
+	gobottest.Assert(t, d.report("open", now.Add(64*time.Second)), true)
+}
+
+func TestDeadbandDriverWithoutHeartbeat(t *testing.T) {
+	d := NewDeadbandDriver(gobot.NewEventer(), "data", 1, 0)
+	now := time.Now()
+
+	gobottest.Assert(t, d.report(1, now), true)
+	gobottest.Assert(t, d.report(1, now.Add(time.Hour)), false)
+}
+
+func TestDeadbandDriverStart(t *testing.T) {
+	source := gobot.NewEventer()
+	source.AddEvent("data")
+	d := NewDeadbandDriver(source, "data", 0.5, 0)
+	gobottest.Assert(t, d.Start(), nil)
+	defer d.Halt()
+
+	sem := make(chan interface{}, 10)
+	d.On(d.Event(Data), func(data interface{}) {
+		sem <- data
+	})
+
+	for _, v := range []float64{20, 20.1, 20.2, 21} {
+		source.Publish("data", v)
+	}
+
+	for _, expected := range []float64{20, 21} {
+		select {
+		case data := <-sem:
+			gobottest.Assert(t, data, expected)
+		case <-time.After(time.Second):
+			t.Fatalf("Data was not published")
+		}
+	}
+}
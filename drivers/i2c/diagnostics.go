@@ -0,0 +1,410 @@
+package i2c
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+	"sort"
+	"sync"
+	"time"
+
+	"gobot.io/x/gobot"
+)
+
+// StateDumper is implemented by anything able to write a human readable
+// snapshot of its internal state, for use in diagnostics.
+type StateDumper interface {
+	DumpState(w io.Writer) error
+}
+
+// DiagnosticConnector wraps a Connector and records the traffic of every
+// Connection it hands out, so the state of a misbehaving bus can be captured
+// on demand with DumpState, or written to a bundle file by DumpStateOnPanic.
+//
+// Drivers are used with it exactly like with the wrapped adaptor:
+//
+//		d := i2c.NewDiagnosticConnector(raspi.NewAdaptor())
+//		sensor := i2c.NewBMP180Driver(d)
+//
+type DiagnosticConnector struct {
+	wrappedConnector
+	mutex       *sync.Mutex
+	connections []*DiagnosticConnection
+}
+
+// NewDiagnosticConnector creates a new DiagnosticConnector wrapping c.
+func NewDiagnosticConnector(c Connector) *DiagnosticConnector {
+	return &DiagnosticConnector{
+		wrappedConnector: wrappedConnector{c},
+		mutex:            &sync.Mutex{},
+	}
+}
+
+// GetConnection returns a recording connection to the device at the
+// specified address and bus.
+func (d *DiagnosticConnector) GetConnection(address int, bus int) (connection Connection, err error) {
+	c, err := d.Connector.GetConnection(address, bus)
+	if err != nil {
+		return nil, err
+	}
+
+	dc := NewDiagnosticConnection(c, address, bus)
+
+	d.mutex.Lock()
+	d.connections = append(d.connections, dc)
+	d.mutex.Unlock()
+
+	return dc, nil
+}
+
+// Connections returns all connections handed out so far.
+func (d *DiagnosticConnector) Connections() []*DiagnosticConnection {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	return append([]*DiagnosticConnection{}, d.connections...)
+}
+
+// DumpState writes the state of every connection handed out so far to w.
+func (d *DiagnosticConnector) DumpState(w io.Writer) (err error) {
+	for _, c := range d.Connections() {
+		if err = c.DumpState(w); err != nil {
+			return
+		}
+	}
+	return
+}
+
+// DiagnosticConnection is a Connection that keeps track of the last values
+// read from and written to each register, transaction, error and retry
+// counters, and the most recent errors returned by the wrapped Connection.
+// A transaction counts as a retry when it repeats a failed transaction with
+// the same operation and register.
+//
+// Besides the SMBus calls, registers are tracked for plain writes starting
+// with the register address, and for reads right after writing a register
+// address alone. Those are recorded byte by byte, assuming the register
+// address increments with every byte as on most devices.
+type DiagnosticConnection struct {
+	Connection
+	address int
+	bus     int
+
+	mutex        *sync.Mutex
+	transactions int
+	errors       int
+	retries      int
+	failedOp     string
+	recentErrors errorChain
+	reads        map[uint8]uint16
+	writes       map[uint8]uint16
+	lastRead     []byte
+	lastWritten  []byte
+	register     int
+}
+
+// NewDiagnosticConnection creates a new DiagnosticConnection wrapping c,
+// which is connected to the device at the given address and bus.
+func NewDiagnosticConnection(c Connection, address int, bus int) *DiagnosticConnection {
+	return &DiagnosticConnection{
+		Connection: c,
+		address:    address,
+		bus:        bus,
+		mutex:      &sync.Mutex{},
+		reads:      make(map[uint8]uint16),
+		writes:     make(map[uint8]uint16),
+		register:   -1,
+	}
+}
+
+// Read data from the i2c device.
+func (c *DiagnosticConnection) Read(data []byte) (read int, err error) {
+	read, err = c.Connection.Read(data)
+	c.record(fmt.Sprintf("Read %d bytes", len(data)), err, func() {
+		c.lastRead = append([]byte{}, data[:read]...)
+		c.recordRead(data[:read])
+	})
+	return
+}
+
+// Write data to the i2c device.
+func (c *DiagnosticConnection) Write(data []byte) (written int, err error) {
+	written, err = c.Connection.Write(data)
+	c.record(fmt.Sprintf("Write % x", data), err, func() {
+		c.lastWritten = append([]byte{}, data...)
+		c.recordWrite(data)
+	})
+	return
+}
+
+// ReadByte reads a single byte from the i2c device.
+func (c *DiagnosticConnection) ReadByte() (val byte, err error) {
+	val, err = c.Connection.ReadByte()
+	c.record("ReadByte", err, func() {
+		c.lastRead = []byte{val}
+		c.recordRead([]byte{val})
+	})
+	return
+}
+
+// ReadByteData reads a byte value for a register on the i2c device.
+func (c *DiagnosticConnection) ReadByteData(reg uint8) (val uint8, err error) {
+	val, err = c.Connection.ReadByteData(reg)
+	c.record(fmt.Sprintf("ReadByteData 0x%02x", reg), err, func() {
+		c.reads[reg] = uint16(val)
+	})
+	return
+}
+
+// ReadWordData reads a word value for a register on the i2c device.
+func (c *DiagnosticConnection) ReadWordData(reg uint8) (val uint16, err error) {
+	val, err = c.Connection.ReadWordData(reg)
+	c.record(fmt.Sprintf("ReadWordData 0x%02x", reg), err, func() {
+		c.reads[reg] = val
+	})
+	return
+}
+
+// WriteByte writes a single byte to the i2c device.
+func (c *DiagnosticConnection) WriteByte(val byte) (err error) {
+	err = c.Connection.WriteByte(val)
+	c.record(fmt.Sprintf("WriteByte 0x%02x", val), err, func() {
+		c.lastWritten = []byte{val}
+		c.recordWrite([]byte{val})
+	})
+	return
+}
+
+// WriteByteData writes a byte value to a register on the i2c device.
+func (c *DiagnosticConnection) WriteByteData(reg uint8, val uint8) (err error) {
+	err = c.Connection.WriteByteData(reg, val)
+	c.record(fmt.Sprintf("WriteByteData 0x%02x", reg), err, func() {
+		c.writes[reg] = uint16(val)
+	})
+	return
+}
+
+// WriteWordData writes a word value to a register on the i2c device.
+func (c *DiagnosticConnection) WriteWordData(reg uint8, val uint16) (err error) {
+	err = c.Connection.WriteWordData(reg, val)
+	c.record(fmt.Sprintf("WriteWordData 0x%02x", reg), err, func() {
+		c.writes[reg] = val
+	})
+	return
+}
+
+// WriteBlockData writes a block of bytes to a register on the i2c device.
+func (c *DiagnosticConnection) WriteBlockData(reg uint8, b []byte) (err error) {
+	err = c.Connection.WriteBlockData(reg, b)
+	c.record(fmt.Sprintf("WriteBlockData 0x%02x", reg), err, func() {
+		c.lastWritten = append([]byte{reg}, b...)
+		c.recordWrite(c.lastWritten)
+	})
+	return
+}
+
+// Capabilities returns the capabilities of the wrapped connection.
+func (c *DiagnosticConnection) Capabilities() Capability {
+	return capabilitiesOf(c.Connection)
+}
+
+// record updates the counters for the transaction op, and calls success to
+// remember the transferred data when the transaction did not fail.
+func (c *DiagnosticConnection) record(op string, err error, success func()) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.transactions++
+	if op == c.failedOp {
+		c.retries++
+	}
+	if err != nil {
+		c.errors++
+		c.failedOp = op
+		c.recentErrors.add(op, err)
+		return
+	}
+	c.failedOp = ""
+	success()
+}
+
+// recordWrite records the registers written by a plain write of data. A
+// register address alone selects the register for the next read.
+func (c *DiagnosticConnection) recordWrite(data []byte) {
+	c.register = -1
+	if len(data) == 1 {
+		c.register = int(data[0])
+		return
+	}
+	for i, val := range data[1:] {
+		c.writes[data[0]+uint8(i)] = uint16(val)
+	}
+}
+
+// recordRead records the registers read by a plain read of data, if a
+// register was selected before.
+func (c *DiagnosticConnection) recordRead(data []byte) {
+	if c.register < 0 {
+		return
+	}
+	for i, val := range data {
+		c.reads[uint8(c.register)+uint8(i)] = uint16(val)
+	}
+	c.register = -1
+}
+
+// DumpState writes the recorded state of the connection to w.
+func (c *DiagnosticConnection) DumpState(w io.Writer) (err error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	p := &errWriter{w: w}
+	p.printf("i2c connection bus=%d address=0x%02x\n", c.bus, c.address)
+	p.printf("  transactions: %d\n", c.transactions)
+	p.printf("  errors: %d\n", c.errors)
+	p.printf("  retries: %d\n", c.retries)
+	c.recentErrors.dump(p)
+	if c.lastRead != nil {
+		p.printf("  last read: % x\n", c.lastRead)
+	}
+	if c.lastWritten != nil {
+		p.printf("  last written: % x\n", c.lastWritten)
+	}
+	for _, reg := range sortedRegisters(c.reads) {
+		p.printf("  read  register 0x%02x: 0x%04x\n", reg, c.reads[reg])
+	}
+	for _, reg := range sortedRegisters(c.writes) {
+		p.printf("  write register 0x%02x: 0x%04x\n", reg, c.writes[reg])
+	}
+	return p.err
+}
+
+// WriteDiagnosticsBundle writes the state of all dumpers to the file at path,
+// replacing any existing content.
+func WriteDiagnosticsBundle(path string, dumpers ...StateDumper) (err error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+	}()
+
+	return writeDiagnostics(f, "", dumpers...)
+}
+
+// DumpStateOnPanic writes a diagnostics bundle with the state of all dumpers,
+// the panic value and the stack trace to the file at path when the calling
+// goroutine panics, then continues panicking. It must be deferred:
+//
+//		defer i2c.DumpStateOnPanic("/var/log/robot-diagnostics.txt", d)
+//
+// A deferred function only recovers panics of its own goroutine, panics in
+// other goroutines, like the polling loops of drivers, end the program
+// without a bundle. Defer it in every goroutine of interest, use
+// DumpStateOnEvent for errors published by drivers, or call
+// WriteDiagnosticsBundle before stopping a robot.
+func DumpStateOnPanic(path string, dumpers ...StateDumper) {
+	r := recover()
+	if r == nil {
+		return
+	}
+
+	if f, err := os.Create(path); err == nil {
+		stack := make([]byte, 64*1024)
+		stack = stack[:runtime.Stack(stack, false)]
+		writeDiagnostics(f, fmt.Sprintf("panic: %v\n\n%s", r, stack), dumpers...)
+		f.Close()
+	}
+	panic(r)
+}
+
+// DumpStateOnEvent writes a diagnostics bundle with the state of all dumpers
+// and the event data to the file at path whenever source publishes event,
+// like the Error event of a driver:
+//
+//		i2c.DumpStateOnEvent("/var/log/robot-diagnostics.txt", sensor, i2c.Error, d)
+//
+func DumpStateOnEvent(path string, source gobot.Eventer, event string, dumpers ...StateDumper) error {
+	return source.On(event, func(data interface{}) {
+		if f, err := os.Create(path); err == nil {
+			writeDiagnostics(f, fmt.Sprintf("event %s: %v", event, data), dumpers...)
+			f.Close()
+		}
+	})
+}
+
+func writeDiagnostics(w io.Writer, cause string, dumpers ...StateDumper) error {
+	p := &errWriter{w: w}
+	p.printf("gobot i2c diagnostics %s\n", time.Now().Format(time.RFC3339Nano))
+	if cause != "" {
+		p.printf("%s\n", cause)
+	}
+	if p.err != nil {
+		return p.err
+	}
+
+	for _, d := range dumpers {
+		if err := d.DumpState(w); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// maxRecentErrors is the number of errors kept by an errorChain.
+const maxRecentErrors = 8
+
+// errorChain keeps the most recent errors together with the operation that
+// failed and the time of the failure, oldest first.
+type errorChain []chainedError
+
+type chainedError struct {
+	op   string
+	err  error
+	time time.Time
+}
+
+func (e *errorChain) add(op string, err error) {
+	*e = append(*e, chainedError{op: op, err: err, time: time.Now()})
+	if len(*e) > maxRecentErrors {
+		*e = (*e)[len(*e)-maxRecentErrors:]
+	}
+}
+
+func (e errorChain) dump(p *errWriter) {
+	if len(e) == 0 {
+		return
+	}
+	p.printf("  last error: %v\n", e[len(e)-1].err)
+	p.printf("  recent errors:\n")
+	for _, c := range e {
+		p.printf("    %s %s: %v\n", c.time.Format(time.RFC3339Nano), c.op, c.err)
+	}
+}
+
+func sortedRegisters(m map[uint8]uint16) []uint8 {
+	regs := make([]uint8, 0, len(m))
+	for reg := range m {
+		regs = append(regs, reg)
+	}
+	sort.Slice(regs, func(i, j int) bool { return regs[i] < regs[j] })
+	return regs
+}
+
+// errWriter remembers the first error while printing, so a dump can be
+// written without checking every single call.
+type errWriter struct {
+	w   io.Writer
+	err error
+}
+
+func (p *errWriter) printf(format string, a ...interface{}) {
+	if p.err != nil {
+		return
+	}
+	_, p.err = fmt.Fprintf(p.w, format, a...)
+}
@@ -0,0 +1,214 @@
+package i2c
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"gobot.io/x/gobot"
+	"gobot.io/x/gobot/gobottest"
+)
+
+var _ gobot.Connection = (*DiagnosticConnector)(nil)
+var _ Connector = (*DiagnosticConnector)(nil)
+var _ StateDumper = (*DiagnosticConnection)(nil)
+
+func initTestDiagnosticConnectorWithStubbedAdaptor() (*DiagnosticConnector, *i2cTestAdaptor) {
+	a := newI2cTestAdaptor()
+	return NewDiagnosticConnector(a), a
+}
+
+func TestDiagnosticConnectorName(t *testing.T) {
+	d, a := initTestDiagnosticConnectorWithStubbedAdaptor()
+	d.SetName("diag")
+	gobottest.Assert(t, a.Name(), "diag")
+	gobottest.Assert(t, d.Name(), "diag")
+	gobottest.Assert(t, d.Connect(), nil)
+	gobottest.Assert(t, d.Finalize(), nil)
+}
+
+func TestDiagnosticConnectorGetConnectionError(t *testing.T) {
+	d, a := initTestDiagnosticConnectorWithStubbedAdaptor()
+	a.Testi2cConnectErr(true)
+	_, err := d.GetConnection(0x10, 1)
+	gobottest.Assert(t, err, errors.New("Invalid i2c connection"))
+	gobottest.Assert(t, len(d.Connections()), 0)
+}
+
+func TestDiagnosticConnectorDumpState(t *testing.T) {
+	d, a := initTestDiagnosticConnectorWithStubbedAdaptor()
+	a.i2cReadImpl = func(b []byte) (int, error) {
+		copy(b, []byte{0x34, 0x12})
+		return len(b), nil
+	}
+
+	c, err := d.GetConnection(0x5a, 1)
+	gobottest.Assert(t, err, nil)
+	c.ReadWordData(0x07)
+	c.WriteByteData(0x24, 0xaa)
+
+	a.i2cReadImpl = func(b []byte) (int, error) {
+		return 0, errors.New("read error")
+	}
+	_, err = c.ReadByteData(0x06)
+	gobottest.Assert(t, err, errors.New("read error"))
+
+	var buf bytes.Buffer
+	gobottest.Assert(t, d.DumpState(&buf), nil)
+	out := buf.String()
+	gobottest.Assert(t, strings.Contains(out, "bus=1 address=0x5a"), true)
+	gobottest.Assert(t, strings.Contains(out, "transactions: 3"), true)
+	gobottest.Assert(t, strings.Contains(out, "errors: 1"), true)
+	gobottest.Assert(t, strings.Contains(out, "last error: read error"), true)
+	gobottest.Assert(t, strings.Contains(out, "read  register 0x07: 0x1234"), true)
+	gobottest.Assert(t, strings.Contains(out, "write register 0x24: 0x00aa"), true)
+	gobottest.Assert(t, strings.Contains(out, "register 0x06"), false)
+}
+
+func TestDiagnosticConnectionWriteRead(t *testing.T) {
+	d, a := initTestDiagnosticConnectorWithStubbedAdaptor()
+	a.i2cReadImpl = func(b []byte) (int, error) {
+		copy(b, []byte{0x5a, 0x10})
+		return len(b), nil
+	}
+
+	c, _ := d.GetConnection(0x77, 1)
+	c.Write([]byte{0xf4, 0x2e})
+	c.Write([]byte{0xf6})
+	c.Read(make([]byte, 2))
+	c.WriteByte(0xd0)
+	c.ReadByte()
+	// a read without selecting a register is not attributed to one
+	c.Read(make([]byte, 1))
+
+	var buf bytes.Buffer
+	gobottest.Assert(t, d.DumpState(&buf), nil)
+	out := buf.String()
+	gobottest.Assert(t, strings.Contains(out, "write register 0xf4: 0x002e"), true)
+	gobottest.Assert(t, strings.Contains(out, "read  register 0xf6: 0x005a"), true)
+	gobottest.Assert(t, strings.Contains(out, "read  register 0xf7: 0x0010"), true)
+	gobottest.Assert(t, strings.Contains(out, "read  register 0xd0: 0x005a"), true)
+	gobottest.Assert(t, strings.Count(out, "read  register"), 3)
+}
+
+func TestDiagnosticConnectionRetries(t *testing.T) {
+	d, a := initTestDiagnosticConnectorWithStubbedAdaptor()
+	fail := true
+	a.i2cReadImpl = func(b []byte) (int, error) {
+		if fail {
+			return 0, errors.New("nack")
+		}
+		copy(b, []byte{0x12})
+		return len(b), nil
+	}
+
+	c, _ := d.GetConnection(0x40, 1)
+	c.ReadByteData(0x01)
+	c.ReadByteData(0x01)
+	// a different register is not a retry
+	c.ReadByteData(0x02)
+	fail = false
+	c.ReadByteData(0x02)
+	c.ReadByteData(0x02)
+	c.WriteBlockData(0x10, []byte{0xaa, 0xbb})
+
+	var buf bytes.Buffer
+	gobottest.Assert(t, d.DumpState(&buf), nil)
+	out := buf.String()
+	gobottest.Assert(t, strings.Contains(out, "errors: 3"), true)
+	gobottest.Assert(t, strings.Contains(out, "retries: 2"), true)
+	gobottest.Assert(t, strings.Count(out, "ReadByteData 0x01: nack"), 2)
+	gobottest.Assert(t, strings.Count(out, "ReadByteData 0x02: nack"), 1)
+	gobottest.Assert(t, strings.Contains(out, "write register 0x10: 0x00aa"), true)
+	gobottest.Assert(t, strings.Contains(out, "write register 0x11: 0x00bb"), true)
+}
+
+func TestDiagnosticConnectionRecentErrors(t *testing.T) {
+	d, a := initTestDiagnosticConnectorWithStubbedAdaptor()
+	a.i2cReadImpl = func(b []byte) (int, error) {
+		return 0, errors.New("nack")
+	}
+
+	c, _ := d.GetConnection(0x40, 1)
+	for reg := uint8(0); reg < maxRecentErrors+2; reg++ {
+		c.ReadByteData(reg)
+	}
+
+	var buf bytes.Buffer
+	gobottest.Assert(t, d.DumpState(&buf), nil)
+	out := buf.String()
+	gobottest.Assert(t, strings.Count(out, ": nack"), maxRecentErrors+1)
+	gobottest.Assert(t, strings.Contains(out, "ReadByteData 0x01:"), false)
+	gobottest.Assert(t, strings.Contains(out, "ReadByteData 0x02:"), true)
+	gobottest.Assert(t, strings.Contains(out, "ReadByteData 0x09:"), true)
+}
+
+func TestWriteDiagnosticsBundle(t *testing.T) {
+	d, _ := initTestDiagnosticConnectorWithStubbedAdaptor()
+	c, _ := d.GetConnection(0x40, 0)
+	c.WriteByte(0x01)
+
+	dir, _ := ioutil.TempDir("", "gobot")
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "bundle.txt")
+
+	gobottest.Assert(t, WriteDiagnosticsBundle(path, d), nil)
+	content, _ := ioutil.ReadFile(path)
+	gobottest.Assert(t, strings.Contains(string(content), "address=0x40"), true)
+	gobottest.Assert(t, strings.Contains(string(content), "last written: 01"), true)
+}
+
+func TestDumpStateOnPanic(t *testing.T) {
+	d, _ := initTestDiagnosticConnectorWithStubbedAdaptor()
+	d.GetConnection(0x40, 0)
+
+	dir, _ := ioutil.TempDir("", "gobot")
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "bundle.txt")
+
+	func() {
+		defer func() {
+			gobottest.Assert(t, recover(), "boom")
+		}()
+		defer DumpStateOnPanic(path, d)
+		panic("boom")
+	}()
+
+	content, _ := ioutil.ReadFile(path)
+	gobottest.Assert(t, strings.Contains(string(content), "panic: boom"), true)
+	gobottest.Assert(t, strings.Contains(string(content), "address=0x40"), true)
+}
+
+func TestDumpStateOnEvent(t *testing.T) {
+	d, _ := initTestDiagnosticConnectorWithStubbedAdaptor()
+	d.GetConnection(0x40, 0)
+
+	dir, _ := ioutil.TempDir("", "gobot")
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "bundle.txt")
+
+	sensor := gobot.NewEventer()
+	sensor.AddEvent(Error)
+	gobottest.Assert(t, DumpStateOnEvent(path, sensor, Error, d), nil)
+
+	done := make(chan bool)
+	sensor.On(Error, func(data interface{}) {
+		close(done)
+	})
+	sensor.Publish(Error, errors.New("read error"))
+	<-done
+
+	// handlers run concurrently, wait for the bundle to be complete
+	var content []byte
+	for i := 0; i < 100 && !strings.Contains(string(content), "address=0x40"); i++ {
+		time.Sleep(time.Millisecond)
+		content, _ = ioutil.ReadFile(path)
+	}
+	gobottest.Assert(t, strings.Contains(string(content), "event error: read error"), true)
+	gobottest.Assert(t, strings.Contains(string(content), "address=0x40"), true)
+}
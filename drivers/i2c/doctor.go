@@ -0,0 +1,177 @@
+package i2c
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"time"
+)
+
+// DoctorDevice is a device the Doctor expects on a bus. When IDs is not
+// empty, the value of IDRegister has to be one of them.
+type DoctorDevice struct {
+	Name       string
+	Bus        int
+	Address    int
+	IDRegister uint8
+	IDs        []uint8
+}
+
+// DoctorCheck is the outcome of a single check, Err is nil if it passed.
+type DoctorCheck struct {
+	Name string
+	Err  error
+}
+
+// DoctorReport is the outcome of all checks of a Doctor run.
+type DoctorReport struct {
+	Checks []DoctorCheck
+}
+
+// Passed returns true if every check passed.
+func (r *DoctorReport) Passed() bool {
+	for _, c := range r.Checks {
+		if c.Err != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// WriteTo writes the report to w, one line per check.
+func (r *DoctorReport) WriteTo(w io.Writer) (n int64, err error) {
+	for _, c := range r.Checks {
+		line := "PASS  " + c.Name + "\n"
+		if c.Err != nil {
+			line = "FAIL  " + c.Name + ": " + c.Err.Error() + "\n"
+		}
+		written, err := io.WriteString(w, line)
+		n += int64(written)
+		if err != nil {
+			return n, err
+		}
+	}
+	return n, nil
+}
+
+func (r *DoctorReport) add(name string, err error) bool {
+	r.Checks = append(r.Checks, DoctorCheck{Name: name, Err: err})
+	return err == nil
+}
+
+// Doctor validates the wiring and setup of I2C devices before a robot is
+// started, turning the usual read errors of Start into a report naming the
+// cause. For every bus of the devices it runs CheckBus, if set, and for
+// every device it checks that it responds at its address, that its ID
+// matches, and that the bus error rate over a few seconds of reads stays
+// acceptable.
+type Doctor struct {
+	connector Connector
+	devices   []DoctorDevice
+
+	// CheckBus checks that a bus is usable before its devices are checked,
+	// e.g. that its device file exists. Leave nil to skip the check.
+	CheckBus func(bus int) error
+
+	// Duration is the time spent measuring the bus error rate of a device
+	Duration time.Duration
+
+	// ReadInterval is the pause between two reads measuring the error rate
+	ReadInterval time.Duration
+
+	// MaxErrorRate is the highest acceptable share of failed reads
+	MaxErrorRate float64
+}
+
+// NewDoctor returns a new Doctor checking devices on connector. Error rates
+// are measured for three seconds per device with a read every 5 ms, up to
+// 1% of failed reads are accepted.
+func NewDoctor(connector Connector, devices ...DoctorDevice) *Doctor {
+	return &Doctor{
+		connector:    connector,
+		devices:      devices,
+		Duration:     3 * time.Second,
+		ReadInterval: 5 * time.Millisecond,
+		MaxErrorRate: 0.01,
+	}
+}
+
+// Run runs all checks and returns the report.
+func (d *Doctor) Run() *DoctorReport {
+	r := &DoctorReport{}
+
+	buses := map[int]bool{}
+	for _, dev := range d.devices {
+		buses[dev.Bus] = true
+	}
+	sorted := []int{}
+	for bus := range buses {
+		sorted = append(sorted, bus)
+	}
+	sort.Ints(sorted)
+
+	for _, bus := range sorted {
+		if d.CheckBus != nil {
+			buses[bus] = r.add(fmt.Sprintf("bus %d", bus), d.CheckBus(bus))
+		}
+	}
+
+	for _, dev := range d.devices {
+		if !buses[dev.Bus] {
+			r.add(d.deviceName(dev), fmt.Errorf("skipped, bus %d is not usable", dev.Bus))
+			continue
+		}
+		d.checkDevice(r, dev)
+	}
+	return r
+}
+
+func (d *Doctor) deviceName(dev DoctorDevice) string {
+	return fmt.Sprintf("%s at bus %d address 0x%02X", dev.Name, dev.Bus, dev.Address)
+}
+
+func (d *Doctor) checkDevice(r *DoctorReport, dev DoctorDevice) {
+	name := d.deviceName(dev)
+
+	conn, err := d.connector.GetConnection(dev.Address, dev.Bus)
+	if !r.add(name+" connection", err) {
+		return
+	}
+
+	id, err := conn.ReadByteData(dev.IDRegister)
+	if err != nil {
+		err = fmt.Errorf("no response (%v), check wiring, power and address", err)
+	}
+	if !r.add(name+" responds", err) {
+		return
+	}
+
+	if len(dev.IDs) > 0 {
+		err = fmt.Errorf("unexpected ID 0x%02X, expected one of % X", id, dev.IDs)
+		for _, expected := range dev.IDs {
+			if id == expected {
+				err = nil
+			}
+		}
+		r.add(name+" ID", err)
+	}
+
+	if d.Duration > 0 {
+		r.add(name+" bus error rate", d.measureErrorRate(conn, dev.IDRegister))
+	}
+}
+
+func (d *Doctor) measureErrorRate(conn Connection, reg uint8) error {
+	reads, failed := 0, 0
+	for start := time.Now(); time.Since(start) < d.Duration; reads++ {
+		if _, err := conn.ReadByteData(reg); err != nil {
+			failed++
+		}
+		time.Sleep(d.ReadInterval)
+	}
+
+	if float64(failed) > float64(reads)*d.MaxErrorRate {
+		return fmt.Errorf("%d of %d reads failed, check cabling, pull-ups and bus speed", failed, reads)
+	}
+	return nil
+}
@@ -0,0 +1,128 @@
+package i2c
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"gobot.io/x/gobot/gobottest"
+)
+
+func initTestDoctor(devices ...DoctorDevice) (*Doctor, *i2cTestAdaptor) {
+	a := newI2cTestAdaptor()
+	a.i2cReadImpl = func(b []byte) (int, error) {
+		b[0] = 0x60
+		return 1, nil
+	}
+	d := NewDoctor(a, devices...)
+	d.Duration = 0
+	d.ReadInterval = 0
+	return d, a
+}
+
+func TestDoctorPassed(t *testing.T) {
+	d, _ := initTestDoctor(DoctorDevice{Name: "BME280", Bus: 1, Address: 0x76, IDRegister: 0xD0, IDs: []uint8{0x58, 0x60}})
+	d.Duration = 5 * time.Millisecond
+
+	r := d.Run()
+	gobottest.Assert(t, r.Passed(), true)
+	gobottest.Assert(t, len(r.Checks), 4)
+
+	buf := &bytes.Buffer{}
+	r.WriteTo(buf)
+	gobottest.Assert(t, buf.String(), "PASS  BME280 at bus 1 address 0x76 connection\n"+
+		"PASS  BME280 at bus 1 address 0x76 responds\n"+
+		"PASS  BME280 at bus 1 address 0x76 ID\n"+
+		"PASS  BME280 at bus 1 address 0x76 bus error rate\n")
+}
+
+func TestDoctorWrongID(t *testing.T) {
+	d, _ := initTestDoctor(DoctorDevice{Name: "BMP280", Bus: 1, Address: 0x76, IDRegister: 0xD0, IDs: []uint8{0x58}})
+
+	r := d.Run()
+	gobottest.Assert(t, r.Passed(), false)
+	gobottest.Assert(t, r.Checks[2].Err, errors.New("unexpected ID 0x60, expected one of 58"))
+}
+
+func TestDoctorNoResponse(t *testing.T) {
+	d, a := initTestDoctor(DoctorDevice{Name: "BH1750", Bus: 1, Address: 0x23})
+	a.i2cReadImpl = func(b []byte) (int, error) {
+		return 0, errors.New("nack")
+	}
+
+	r := d.Run()
+	gobottest.Assert(t, r.Passed(), false)
+	gobottest.Assert(t, len(r.Checks), 2)
+	gobottest.Assert(t, r.Checks[1].Err, errors.New("no response (nack), check wiring, power and address"))
+}
+
+func TestDoctorConnectionError(t *testing.T) {
+	d, a := initTestDoctor(DoctorDevice{Name: "BH1750", Bus: 1, Address: 0x23})
+	a.i2cConnectErr = true
+
+	r := d.Run()
+	gobottest.Assert(t, len(r.Checks), 1)
+	gobottest.Assert(t, r.Checks[0].Err, errors.New("Invalid i2c connection"))
+}
+
+func TestDoctorErrorRate(t *testing.T) {
+	d, a := initTestDoctor(DoctorDevice{Name: "BH1750", Bus: 1, Address: 0x23})
+	d.Duration = 5 * time.Millisecond
+	reads := 0
+	a.i2cReadImpl = func(b []byte) (int, error) {
+		reads++
+		if reads > 1 && reads%10 == 0 {
+			return 0, errors.New("nack")
+		}
+		return 1, nil
+	}
+
+	r := d.Run()
+	gobottest.Assert(t, r.Passed(), false)
+	gobottest.Assert(t, strings.HasSuffix(r.Checks[2].Err.Error(), "reads failed, check cabling, pull-ups and bus speed"), true)
+}
+
+func TestDoctorReadInterval(t *testing.T) {
+	d, a := initTestDoctor(DoctorDevice{Name: "BH1750", Bus: 1, Address: 0x23})
+	d.Duration = 20 * time.Millisecond
+	d.ReadInterval = 5 * time.Millisecond
+	reads := 0
+	a.i2cReadImpl = func(b []byte) (int, error) {
+		reads++
+		return 1, nil
+	}
+
+	r := d.Run()
+	gobottest.Assert(t, r.Passed(), true)
+	// one read for the response check, up to 4 paced ones for the error rate
+	gobottest.Assert(t, reads <= 6, true)
+}
+
+func TestDoctorCheckBus(t *testing.T) {
+	d, _ := initTestDoctor(
+		DoctorDevice{Name: "A", Bus: 1, Address: 0x10},
+		DoctorDevice{Name: "B", Bus: 2, Address: 0x10},
+	)
+	d.CheckBus = func(bus int) error {
+		if bus != 1 {
+			return errors.New("missing")
+		}
+		return nil
+	}
+
+	r := d.Run()
+	gobottest.Assert(t, r.Passed(), false)
+	gobottest.Assert(t, r.Checks[0].Err, nil)
+	gobottest.Assert(t, r.Checks[1].Name, "bus 2")
+	gobottest.Assert(t, r.Checks[1].Err, errors.New("missing"))
+	for _, c := range r.Checks {
+		switch c.Name {
+		case "A at bus 1 address 0x10 responds":
+			gobottest.Assert(t, c.Err, nil)
+		case "B at bus 2 address 0x10":
+			gobottest.Assert(t, c.Err, errors.New("skipped, bus 2 is not usable"))
+		}
+	}
+}
@@ -0,0 +1,358 @@
+package i2c
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"gobot.io/x/gobot"
+)
+
+const (
+	// CalibrationDue event, published with the reason a recalibration is due
+	CalibrationDue = "calibrationdue"
+
+	// driftHistorySize is the number of drift samples kept
+	driftHistorySize = 1000
+)
+
+// DriftSample is a reading of a sensor compared to its reference.
+type DriftSample struct {
+	Time      time.Time
+	Reading   float64
+	Reference float64
+	Drift     float64
+}
+
+// DriftTrackerDriver tracks the drift of a sensor over its lifetime. Every
+// sample of the sensor is compared to the latest sample of a reference
+// sensor set with SetReference, or, without a reference, to the baseline
+// taken right after the last calibration under known conditions. A CalibrationDue event is
+// published when the average drift of the recent samples exceeds the bound,
+// or when the calibration interval elapsed.
+//
+// The time of the last calibration and the history are only kept in memory.
+// To carry them across restarts, store them and restore them with
+// SetCalibrated and LoadHistory before Start; otherwise the calendar starts
+// over when the driver is created.
+//
+// Adds the following API Commands:
+//	"Drift" - See DriftTrackerDriver.Drift
+//	"History" - See DriftTrackerDriver.History
+//	"Calibrated" - See DriftTrackerDriver.Calibrated
+type DriftTrackerDriver struct {
+	name           string
+	sensor         gobot.Eventer
+	sensorEvent    string
+	reference      gobot.Eventer
+	referenceEvent string
+	bound          float64
+	window         int
+	interval       time.Duration
+	mutex          *sync.Mutex
+	calibrated     time.Time
+	baseline       float64
+	hasRef         bool
+	due            bool
+	dueReason      string
+	history        []DriftSample
+	halt           chan bool
+	gobot.Eventer
+	gobot.Commander
+}
+
+// NewDriftTrackerDriver returns a new DriftTrackerDriver tracking the data of
+// the event published by sensor, and reporting drifts larger than bound.
+// The drift is averaged over the last 10 samples. An error is returned
+// without a sensor.
+func NewDriftTrackerDriver(sensor gobot.Eventer, event string, bound float64) (*DriftTrackerDriver, error) {
+	if sensor == nil {
+		return nil, errors.New("DriftTracker needs a sensor")
+	}
+
+	d := &DriftTrackerDriver{
+		name:        gobot.DefaultName("DriftTracker"),
+		sensor:      sensor,
+		sensorEvent: event,
+		bound:       bound,
+		window:     10,
+		mutex:      &sync.Mutex{},
+		calibrated: time.Now(),
+		Eventer:    gobot.NewEventer(),
+		Commander:  gobot.NewCommander(),
+	}
+
+	d.AddEvent(CalibrationDue)
+
+	d.AddCommand("Drift", func(params map[string]interface{}) interface{} {
+		val, err := d.Drift()
+		return map[string]interface{}{"val": val, "err": err}
+	})
+	d.AddCommand("History", func(params map[string]interface{}) interface{} {
+		return d.History()
+	})
+	d.AddCommand("Calibrated", func(params map[string]interface{}) interface{} {
+		d.Calibrated()
+		return nil
+	})
+
+	return d, nil
+}
+
+// Name returns the DriftTrackerDrivers name
+func (d *DriftTrackerDriver) Name() string { return d.name }
+
+// SetName sets the DriftTrackerDrivers name
+func (d *DriftTrackerDriver) SetName(n string) { d.name = n }
+
+// Connection returns nil, a drift tracker has no connection
+func (d *DriftTrackerDriver) Connection() gobot.Connection { return nil }
+
+// SetReference compares the samples of the sensor to the data of the event
+// published by reference, instead of to the baseline. It must be called
+// before Start, a nil reference switches back to the baseline.
+func (d *DriftTrackerDriver) SetReference(reference gobot.Eventer, event string) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	d.reference = reference
+	d.referenceEvent = event
+	d.hasRef = false
+}
+
+// SetWindow sets the number of recent samples the drift is averaged over.
+func (d *DriftTrackerDriver) SetWindow(n int) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	d.window = n
+}
+
+// SetCalibrated sets the time of the last calibration, for calibrations
+// recorded before the driver was created. It does not clear the history.
+func (d *DriftTrackerDriver) SetCalibrated(t time.Time) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	d.calibrated = t
+	d.due = false
+}
+
+// LoadHistory restores drift samples recorded since the last calibration,
+// oldest first, e.g. saved from History before a restart. Without a
+// reference the reference of the last sample becomes the baseline.
+func (d *DriftTrackerDriver) LoadHistory(samples []DriftSample) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	d.history = append(append([]DriftSample{}, samples...), d.history...)
+	if len(d.history) > driftHistorySize {
+		d.history = d.history[len(d.history)-driftHistorySize:]
+	}
+	if len(samples) > 0 && d.reference == nil && !d.hasRef {
+		d.baseline = samples[len(samples)-1].Reference
+		d.hasRef = true
+	}
+}
+
+// SetCalibrationInterval sets the time after which a recalibration is due
+// regardless of the drift. Zero disables the calendar reminder.
+func (d *DriftTrackerDriver) SetCalibrationInterval(interval time.Duration) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	d.interval = interval
+}
+
+// Start starts tracking the samples of the sensor and its reference. A
+// calibration interval that already elapsed is reported right away.
+// Emits the Events:
+//	CalibrationDue string - the reason a recalibration is due
+func (d *DriftTrackerDriver) Start() (err error) {
+	halt := make(chan bool)
+	d.halt = halt
+
+	sensor := d.sensor.Subscribe()
+	go func() {
+		ticker := time.NewTicker(time.Minute)
+		defer ticker.Stop()
+		d.checkInterval()
+		for {
+			select {
+			case evt := <-sensor:
+				if evt.Name == d.sensorEvent {
+					d.update(evt.Data)
+				}
+			case <-ticker.C:
+				d.checkInterval()
+			case <-halt:
+				gobot.UnsubscribeAndDrain(d.sensor, sensor)
+				return
+			}
+		}
+	}()
+
+	if d.reference != nil {
+		reference := d.reference.Subscribe()
+		go func() {
+			for {
+				select {
+				case evt := <-reference:
+					if evt.Name == d.referenceEvent {
+						d.updateReference(evt.Data)
+					}
+				case <-halt:
+					gobot.UnsubscribeAndDrain(d.reference, reference)
+					return
+				}
+			}
+		}()
+	}
+	return
+}
+
+// Halt stops tracking.
+func (d *DriftTrackerDriver) Halt() (err error) {
+	if d.halt != nil {
+		close(d.halt)
+		d.halt = nil
+	}
+	return
+}
+
+// Calibrated records a calibration of the sensor. It restarts the calendar
+// interval and clears the history. Without a reference the next sample of
+// the sensor becomes the new baseline.
+func (d *DriftTrackerDriver) Calibrated() {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	d.calibrated = time.Now()
+	d.history = nil
+	d.due = false
+	if d.reference == nil {
+		d.hasRef = false
+	}
+}
+
+// Health returns gobot.HealthWarning with the reason while a recalibration
+// is due, and gobot.HealthOK otherwise.
+func (d *DriftTrackerDriver) Health() (gobot.HealthState, error) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	if d.due {
+		return gobot.HealthWarning, errors.New("calibration due: " + d.dueReason)
+	}
+	return gobot.HealthOK, nil
+}
+
+// Drift returns the average drift of the recent samples. ErrNotReady is
+// returned until the first sample was compared.
+func (d *DriftTrackerDriver) Drift() (drift float64, err error) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	return d.drift()
+}
+
+// History returns the drift samples since the last calibration, oldest
+// first. Only the latest 1000 samples are kept, in memory.
+func (d *DriftTrackerDriver) History() []DriftSample {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	history := make([]DriftSample, len(d.history))
+	copy(history, d.history)
+	return history
+}
+
+func (d *DriftTrackerDriver) drift() (drift float64, err error) {
+	if len(d.history) == 0 {
+		return 0, ErrNotReady
+	}
+
+	recent := d.history
+	if d.window > 0 && len(recent) > d.window {
+		recent = recent[len(recent)-d.window:]
+	}
+	for _, s := range recent {
+		drift += s.Drift
+	}
+	return drift / float64(len(recent)), nil
+}
+
+func (d *DriftTrackerDriver) updateReference(data interface{}) {
+	val, ok := gobot.Facts{"val": data}.Float("val")
+	if !ok {
+		return
+	}
+
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	d.baseline = val
+	d.hasRef = true
+}
+
+func (d *DriftTrackerDriver) update(data interface{}) {
+	val, ok := gobot.Facts{"val": data}.Float("val")
+	if !ok {
+		return
+	}
+
+	d.mutex.Lock()
+	if !d.hasRef {
+		if d.reference != nil {
+			d.mutex.Unlock()
+			return
+		}
+		d.baseline = val
+		d.hasRef = true
+	}
+
+	d.history = append(d.history, DriftSample{
+		Time:      time.Now(),
+		Reading:   val,
+		Reference: d.baseline,
+		Drift:     val - d.baseline,
+	})
+	if len(d.history) > driftHistorySize {
+		d.history = d.history[len(d.history)-driftHistorySize:]
+	}
+
+	drift, _ := d.drift()
+	d.mutex.Unlock()
+
+	if math.Abs(drift) > d.bound {
+		d.calibrationDue(fmt.Sprintf("drift %v exceeds %v", drift, d.bound))
+	}
+	d.checkInterval()
+}
+
+func (d *DriftTrackerDriver) checkInterval() {
+	d.mutex.Lock()
+	elapsed := d.interval > 0 && time.Since(d.calibrated) > d.interval
+	d.mutex.Unlock()
+
+	if elapsed {
+		d.calibrationDue("calibration interval elapsed")
+	}
+}
+
+// calibrationDue publishes CalibrationDue once until the next calibration.
+func (d *DriftTrackerDriver) calibrationDue(reason string) {
+	d.mutex.Lock()
+	due := d.due
+	if !due {
+		d.due = true
+		d.dueReason = reason
+	}
+	d.mutex.Unlock()
+
+	if !due {
+		d.Publish(CalibrationDue, reason)
+	}
+}
@@ -0,0 +1,162 @@
+package i2c
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"gobot.io/x/gobot"
+	"gobot.io/x/gobot/gobottest"
+)
+
+var _ gobot.Driver = (*DriftTrackerDriver)(nil)
+var _ gobot.HealthChecker = (*DriftTrackerDriver)(nil)
+
+func newDriftTestSource() gobot.Eventer {
+	e := gobot.NewEventer()
+	e.AddEvent("data")
+	return e
+}
+
+func TestNewDriftTrackerDriver(t *testing.T) {
+	d, err := NewDriftTrackerDriver(newDriftTestSource(), "data", 0.5)
+	gobottest.Assert(t, err, nil)
+	gobottest.Assert(t, d.Name()[:12], "DriftTracker")
+	gobottest.Assert(t, d.Connection(), nil)
+	gobottest.Refute(t, d.Command("Drift"), nil)
+	gobottest.Refute(t, d.Command("History"), nil)
+	gobottest.Refute(t, d.Command("Calibrated"), nil)
+
+	_, err = d.Drift()
+	gobottest.Assert(t, err, ErrNotReady)
+}
+
+func TestNewDriftTrackerDriverWithoutSensor(t *testing.T) {
+	_, err := NewDriftTrackerDriver(nil, "data", 0.5)
+	gobottest.Assert(t, err, errors.New("DriftTracker needs a sensor"))
+}
+
+func TestDriftTrackerDriverBaseline(t *testing.T) {
+	d, _ := NewDriftTrackerDriver(newDriftTestSource(), "data", 0.5)
+	d.SetWindow(2)
+
+	d.update(20.0)
+	d.update(20.2)
+	drift, err := d.Drift()
+	gobottest.Assert(t, err, nil)
+	gobottest.Assert(t, drift > 0.09 && drift < 0.11, true)
+	gobottest.Assert(t, len(d.History()), 2)
+	gobottest.Assert(t, d.History()[1].Reference, 20.0)
+
+	d.Calibrated()
+	gobottest.Assert(t, len(d.History()), 0)
+	d.update(21)
+	gobottest.Assert(t, d.History()[0].Reference, 21.0)
+}
+
+func TestDriftTrackerDriverCalibrationDue(t *testing.T) {
+	sensor := newDriftTestSource()
+	reference := newDriftTestSource()
+	d, _ := NewDriftTrackerDriver(sensor, "data", 0.5)
+	d.SetReference(reference, "data")
+	gobottest.Assert(t, d.Start(), nil)
+	defer d.Halt()
+
+	sem := make(chan interface{}, 1)
+	d.Once(CalibrationDue, func(data interface{}) {
+		sem <- data
+	})
+
+	// samples without a reference are ignored
+	sensor.Publish("data", 30)
+	time.Sleep(10 * time.Millisecond)
+	gobottest.Assert(t, len(d.History()), 0)
+
+	reference.Publish("data", 20)
+	time.Sleep(10 * time.Millisecond)
+	sensor.Publish("data", 21)
+
+	select {
+	case reason := <-sem:
+		gobottest.Assert(t, strings.HasPrefix(reason.(string), "drift 1 exceeds"), true)
+	case <-time.After(time.Second):
+		t.Errorf("CalibrationDue was not published")
+	}
+}
+
+func TestDriftTrackerDriverHealth(t *testing.T) {
+	d, _ := NewDriftTrackerDriver(newDriftTestSource(), "data", 0.5)
+	state, err := d.Health()
+	gobottest.Assert(t, state, gobot.HealthOK)
+	gobottest.Assert(t, err, nil)
+
+	d.update(20.0)
+	d.update(21.5)
+	state, err = d.Health()
+	gobottest.Assert(t, state, gobot.HealthWarning)
+	gobottest.Assert(t, strings.HasPrefix(err.Error(), "calibration due: drift"), true)
+
+	d.Calibrated()
+	state, _ = d.Health()
+	gobottest.Assert(t, state, gobot.HealthOK)
+}
+
+func TestDriftTrackerDriverCalibrationInterval(t *testing.T) {
+	d, _ := NewDriftTrackerDriver(newDriftTestSource(), "data", 0.5)
+	d.SetCalibrationInterval(time.Millisecond)
+
+	sem := make(chan interface{}, 1)
+	d.Once(CalibrationDue, func(data interface{}) {
+		sem <- data
+	})
+
+	time.Sleep(5 * time.Millisecond)
+	d.update(20)
+
+	select {
+	case reason := <-sem:
+		gobottest.Assert(t, reason, "calibration interval elapsed")
+	case <-time.After(time.Second):
+		t.Errorf("CalibrationDue was not published")
+	}
+}
+
+func TestDriftTrackerDriverCalibrationIntervalAtStart(t *testing.T) {
+	d, _ := NewDriftTrackerDriver(newDriftTestSource(), "data", 0.5)
+	d.SetCalibrationInterval(24 * time.Hour)
+	d.SetCalibrated(time.Now().Add(-48 * time.Hour))
+
+	sem := make(chan interface{}, 1)
+	d.Once(CalibrationDue, func(data interface{}) {
+		sem <- data
+	})
+
+	gobottest.Assert(t, d.Start(), nil)
+	defer d.Halt()
+
+	select {
+	case reason := <-sem:
+		gobottest.Assert(t, reason, "calibration interval elapsed")
+	case <-time.After(time.Second):
+		t.Errorf("CalibrationDue was not published")
+	}
+}
+
+func TestDriftTrackerDriverLoadHistory(t *testing.T) {
+	d, _ := NewDriftTrackerDriver(newDriftTestSource(), "data", 0.5)
+	d.SetWindow(3)
+	d.LoadHistory([]DriftSample{
+		{Reading: 20.1, Reference: 20, Drift: 0.1},
+		{Reading: 20.2, Reference: 20, Drift: 0.2},
+	})
+	gobottest.Assert(t, len(d.History()), 2)
+
+	d.update(20.3)
+	history := d.History()
+	gobottest.Assert(t, len(history), 3)
+	gobottest.Assert(t, history[2].Reference, 20.0)
+	drift, err := d.Drift()
+	gobottest.Assert(t, err, nil)
+	gobottest.Assert(t, drift > 0.19 && drift < 0.21, true)
+}
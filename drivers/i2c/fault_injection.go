@@ -0,0 +1,327 @@
+package i2c
+
+import (
+	"errors"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// ErrInjectedNack is returned by transactions failed by a FaultInjector
+var ErrInjectedNack = errors.New("Injected NACK")
+
+// FaultInjector decides which artificial faults are injected into the
+// transactions of FaultConnections, to exercise retry and recovery code
+// under controlled failure conditions. It is safe to reconfigure a
+// FaultInjector while transactions are running.
+type FaultInjector struct {
+	mutex              *sync.Mutex
+	rand               *rand.Rand
+	nackProbability    float64
+	bitFlipProbability float64
+	delay              time.Duration
+	stuck              map[uint8]uint16
+}
+
+// NewFaultInjector creates a new FaultInjector which injects no faults until
+// configured. The seed makes the sequence of injected faults reproducible.
+func NewFaultInjector(seed int64) *FaultInjector {
+	return &FaultInjector{
+		mutex: &sync.Mutex{},
+		rand:  rand.New(rand.NewSource(seed)),
+		stuck: make(map[uint8]uint16),
+	}
+}
+
+// SetNackProbability sets the probability, between 0 and 1, of a transaction
+// failing with ErrInjectedNack without reaching the device.
+func (f *FaultInjector) SetNackProbability(p float64) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	f.nackProbability = p
+}
+
+// SetBitFlipProbability sets the probability, between 0 and 1, of a single
+// random bit being flipped in the data of a successful read.
+func (f *FaultInjector) SetBitFlipProbability(p float64) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	f.bitFlipProbability = p
+}
+
+// SetDelay sets a delay added before every transaction.
+func (f *FaultInjector) SetDelay(d time.Duration) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	f.delay = d
+}
+
+// SetStuckValue makes every byte or word read of reg return val, regardless
+// of the device content. Besides ReadByteData and ReadWordData this covers
+// Read and ReadByte right after the register was selected by writing its
+// address alone. A single byte read returns the low byte of val, longer
+// reads return the high byte first, then the low byte, the order of drivers
+// reading a register pair with Read, like the BMP180; further bytes are read
+// from the device.
+func (f *FaultInjector) SetStuckValue(reg uint8, val uint16) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	f.stuck[reg] = val
+}
+
+// ClearStuckValue stops returning a stuck value for reg.
+func (f *FaultInjector) ClearStuckValue(reg uint8) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	delete(f.stuck, reg)
+}
+
+// before delays and possibly fails a transaction before it reaches the device.
+func (f *FaultInjector) before() error {
+	f.mutex.Lock()
+	delay := f.delay
+	nack := f.nackProbability > 0 && f.rand.Float64() < f.nackProbability
+	f.mutex.Unlock()
+
+	if delay > 0 {
+		time.Sleep(delay)
+	}
+	if nack {
+		return ErrInjectedNack
+	}
+	return nil
+}
+
+// stuckValue returns the stuck value for reg, if any.
+func (f *FaultInjector) stuckValue(reg uint8) (val uint16, ok bool) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	val, ok = f.stuck[reg]
+	return
+}
+
+// flip possibly flips a random bit of the lowest bits of val.
+func (f *FaultInjector) flip(val uint16, bits uint) uint16 {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if f.bitFlipProbability > 0 && f.rand.Float64() < f.bitFlipProbability {
+		val ^= 1 << uint(f.rand.Intn(int(bits)))
+	}
+	return val
+}
+
+// flipBytes possibly flips a random bit in data.
+func (f *FaultInjector) flipBytes(data []byte) {
+	if len(data) == 0 {
+		return
+	}
+
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if f.bitFlipProbability > 0 && f.rand.Float64() < f.bitFlipProbability {
+		bit := f.rand.Intn(len(data) * 8)
+		data[bit/8] ^= 1 << uint(bit%8)
+	}
+}
+
+// FaultConnector wraps a Connector and injects the faults of a FaultInjector
+// into every Connection it hands out. It works against real as well as
+// simulated buses:
+//
+//		faults := i2c.NewFaultInjector(1)
+//		faults.SetNackProbability(0.01)
+//		sensor := i2c.NewBMP180Driver(i2c.NewFaultConnector(raspi.NewAdaptor(), faults))
+//
+type FaultConnector struct {
+	wrappedConnector
+	faults *FaultInjector
+}
+
+// NewFaultConnector creates a new FaultConnector wrapping c.
+func NewFaultConnector(c Connector, faults *FaultInjector) *FaultConnector {
+	return &FaultConnector{
+		wrappedConnector: wrappedConnector{c},
+		faults:           faults,
+	}
+}
+
+// GetConnection returns a fault injecting connection to the device at the
+// specified address and bus.
+func (f *FaultConnector) GetConnection(address int, bus int) (connection Connection, err error) {
+	c, err := f.Connector.GetConnection(address, bus)
+	if err != nil {
+		return nil, err
+	}
+	return NewFaultConnection(c, f.faults), nil
+}
+
+// FaultConnection is a Connection injecting the faults of a FaultInjector.
+type FaultConnection struct {
+	Connection
+	faults   *FaultInjector
+	mutex    *sync.Mutex
+	register int
+}
+
+// NewFaultConnection creates a new FaultConnection wrapping c.
+func NewFaultConnection(c Connection, faults *FaultInjector) *FaultConnection {
+	return &FaultConnection{Connection: c, faults: faults, mutex: &sync.Mutex{}, register: -1}
+}
+
+// selected returns the stuck value of the register selected by the last
+// write, if any.
+func (c *FaultConnection) selected() (val uint16, ok bool) {
+	c.mutex.Lock()
+	reg := c.register
+	c.mutex.Unlock()
+
+	if reg < 0 {
+		return 0, false
+	}
+	return c.faults.stuckValue(uint8(reg))
+}
+
+// selectRegister remembers the register selected by a write of data, or
+// forgets it when data is not a register address alone. Transactions
+// addressing a register themselves pass nil to forget it.
+func (c *FaultConnection) selectRegister(data []byte) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.register = -1
+	if len(data) == 1 {
+		c.register = int(data[0])
+	}
+}
+
+// Read data from the i2c device.
+func (c *FaultConnection) Read(data []byte) (read int, err error) {
+	if err = c.faults.before(); err != nil {
+		return 0, err
+	}
+	read, err = c.Connection.Read(data)
+	if err != nil {
+		return
+	}
+	if stuck, ok := c.selected(); ok {
+		switch {
+		case read == 1:
+			data[0] = byte(stuck)
+		case read > 1:
+			data[0], data[1] = byte(stuck>>8), byte(stuck)
+		}
+		return
+	}
+	c.faults.flipBytes(data[:read])
+	return
+}
+
+// Write data to the i2c device.
+func (c *FaultConnection) Write(data []byte) (written int, err error) {
+	if err = c.faults.before(); err != nil {
+		return 0, err
+	}
+	written, err = c.Connection.Write(data)
+	if err == nil {
+		c.selectRegister(data)
+	}
+	return
+}
+
+// ReadByte reads a single byte from the i2c device.
+func (c *FaultConnection) ReadByte() (val byte, err error) {
+	if err = c.faults.before(); err != nil {
+		return 0, err
+	}
+	val, err = c.Connection.ReadByte()
+	if err != nil {
+		return
+	}
+	if stuck, ok := c.selected(); ok {
+		return byte(stuck), nil
+	}
+	return byte(c.faults.flip(uint16(val), 8)), nil
+}
+
+// ReadByteData reads a byte value for a register on the i2c device.
+func (c *FaultConnection) ReadByteData(reg uint8) (val uint8, err error) {
+	if err = c.faults.before(); err != nil {
+		return 0, err
+	}
+	val, err = c.Connection.ReadByteData(reg)
+	if err != nil {
+		return
+	}
+	c.selectRegister(nil)
+	if stuck, ok := c.faults.stuckValue(reg); ok {
+		return uint8(stuck), nil
+	}
+	return uint8(c.faults.flip(uint16(val), 8)), nil
+}
+
+// ReadWordData reads a word value for a register on the i2c device.
+func (c *FaultConnection) ReadWordData(reg uint8) (val uint16, err error) {
+	if err = c.faults.before(); err != nil {
+		return 0, err
+	}
+	val, err = c.Connection.ReadWordData(reg)
+	if err != nil {
+		return
+	}
+	c.selectRegister(nil)
+	if stuck, ok := c.faults.stuckValue(reg); ok {
+		return stuck, nil
+	}
+	return c.faults.flip(val, 16), nil
+}
+
+// WriteByte writes a single byte to the i2c device.
+func (c *FaultConnection) WriteByte(val byte) (err error) {
+	if err = c.faults.before(); err != nil {
+		return
+	}
+	if err = c.Connection.WriteByte(val); err == nil {
+		c.selectRegister([]byte{val})
+	}
+	return
+}
+
+// WriteByteData writes a byte value to a register on the i2c device.
+func (c *FaultConnection) WriteByteData(reg uint8, val uint8) (err error) {
+	if err = c.faults.before(); err != nil {
+		return
+	}
+	if err = c.Connection.WriteByteData(reg, val); err == nil {
+		c.selectRegister(nil)
+	}
+	return
+}
+
+// WriteWordData writes a word value to a register on the i2c device.
+func (c *FaultConnection) WriteWordData(reg uint8, val uint16) (err error) {
+	if err = c.faults.before(); err != nil {
+		return
+	}
+	if err = c.Connection.WriteWordData(reg, val); err == nil {
+		c.selectRegister(nil)
+	}
+	return
+}
+
+// WriteBlockData writes a block of bytes to a register on the i2c device.
+func (c *FaultConnection) WriteBlockData(reg uint8, b []byte) (err error) {
+	if err = c.faults.before(); err != nil {
+		return
+	}
+	if err = c.Connection.WriteBlockData(reg, b); err == nil {
+		c.selectRegister(nil)
+	}
+	return
+}
+
+// Capabilities returns the capabilities of the wrapped connection.
+func (c *FaultConnection) Capabilities() Capability {
+	return capabilitiesOf(c.Connection)
+}
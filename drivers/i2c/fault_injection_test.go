@@ -0,0 +1,128 @@
+package i2c
+
+import (
+	"testing"
+	"time"
+
+	"gobot.io/x/gobot"
+	"gobot.io/x/gobot/gobottest"
+)
+
+var _ gobot.Connection = (*FaultConnector)(nil)
+var _ Connector = (*FaultConnector)(nil)
+var _ Connection = (*FaultConnection)(nil)
+
+func initTestFaultConnectionWithStubbedAdaptor() (*FaultInjector, Connection, *i2cTestAdaptor) {
+	a := newI2cTestAdaptor()
+	a.i2cReadImpl = func(b []byte) (int, error) {
+		for i := range b {
+			b[i] = 0
+		}
+		return len(b), nil
+	}
+	faults := NewFaultInjector(1)
+	c, _ := NewFaultConnector(a, faults).GetConnection(0x10, 0)
+	return faults, c, a
+}
+
+func TestFaultConnectionNoFaults(t *testing.T) {
+	_, c, a := initTestFaultConnectionWithStubbedAdaptor()
+	val, err := c.ReadWordData(0x01)
+	gobottest.Assert(t, err, nil)
+	gobottest.Assert(t, val, uint16(0))
+	gobottest.Assert(t, c.WriteByteData(0x01, 0x02), nil)
+	gobottest.Assert(t, a.written, []byte{0x01, 0x02})
+}
+
+func TestFaultConnectionNack(t *testing.T) {
+	faults, c, a := initTestFaultConnectionWithStubbedAdaptor()
+	faults.SetNackProbability(1)
+
+	_, err := c.ReadByteData(0x01)
+	gobottest.Assert(t, err, ErrInjectedNack)
+	gobottest.Assert(t, c.WriteByteData(0x01, 0x02), ErrInjectedNack)
+	gobottest.Assert(t, len(a.written), 0)
+
+	faults.SetNackProbability(0)
+	_, err = c.ReadByteData(0x01)
+	gobottest.Assert(t, err, nil)
+}
+
+func TestFaultConnectionBitFlip(t *testing.T) {
+	faults, c, _ := initTestFaultConnectionWithStubbedAdaptor()
+	faults.SetBitFlipProbability(1)
+
+	val, _ := c.ReadWordData(0x01)
+	gobottest.Assert(t, val != 0, true)
+	gobottest.Assert(t, val&(val-1), uint16(0))
+
+	buf := []byte{0, 0, 0}
+	n, _ := c.Read(buf)
+	gobottest.Assert(t, n, 3)
+	gobottest.Assert(t, buf[0]|buf[1]|buf[2] != 0, true)
+}
+
+func TestFaultConnectionStuckValue(t *testing.T) {
+	faults, c, _ := initTestFaultConnectionWithStubbedAdaptor()
+	faults.SetStuckValue(0x07, 0xbeef)
+
+	val, _ := c.ReadWordData(0x07)
+	gobottest.Assert(t, val, uint16(0xbeef))
+	b, _ := c.ReadByteData(0x07)
+	gobottest.Assert(t, b, uint8(0xef))
+	val, _ = c.ReadWordData(0x06)
+	gobottest.Assert(t, val, uint16(0))
+
+	faults.ClearStuckValue(0x07)
+	val, _ = c.ReadWordData(0x07)
+	gobottest.Assert(t, val, uint16(0))
+}
+
+func TestFaultConnectionStuckValueWriteRead(t *testing.T) {
+	faults, c, _ := initTestFaultConnectionWithStubbedAdaptor()
+	faults.SetStuckValue(0x07, 0xbeef)
+
+	c.Write([]byte{0x07})
+	buf := []byte{0, 0, 0}
+	n, err := c.Read(buf)
+	gobottest.Assert(t, err, nil)
+	gobottest.Assert(t, n, 3)
+	gobottest.Assert(t, buf, []byte{0xbe, 0xef, 0x00})
+
+	c.WriteByte(0x07)
+	b, _ := c.ReadByte()
+	gobottest.Assert(t, b, uint8(0xef))
+
+	// writing data besides the register address deselects it
+	c.Write([]byte{0x07, 0x01})
+	n, _ = c.Read(buf)
+	gobottest.Assert(t, buf, []byte{0x00, 0x00, 0x00})
+
+	c.Write([]byte{0x06})
+	b, _ = c.ReadByte()
+	gobottest.Assert(t, b, uint8(0))
+
+	// transactions addressing a register themselves deselect it
+	deselect := []func(){
+		func() { c.WriteByteData(0x01, 0x02) },
+		func() { c.WriteWordData(0x01, 0x0203) },
+		func() { c.WriteBlockData(0x01, []byte{0x02}) },
+		func() { c.ReadByteData(0x01) },
+		func() { c.ReadWordData(0x01) },
+	}
+	for _, f := range deselect {
+		c.Write([]byte{0x07})
+		f()
+		b, _ = c.ReadByte()
+		gobottest.Assert(t, b, uint8(0))
+	}
+}
+
+func TestFaultConnectionDelay(t *testing.T) {
+	faults, c, _ := initTestFaultConnectionWithStubbedAdaptor()
+	faults.SetDelay(10 * time.Millisecond)
+
+	start := time.Now()
+	c.WriteByte(0x01)
+	gobottest.Assert(t, time.Since(start) >= 10*time.Millisecond, true)
+}
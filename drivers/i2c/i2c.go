@@ -4,11 +4,16 @@ import (
 	"errors"
 	"io"
 	"sync"
+
+	"gobot.io/x/gobot"
 )
 
 const (
 	// Error event
 	Error = "error"
+
+	// Data event
+	Data = "data"
 )
 
 const (
@@ -62,6 +67,44 @@ type Connector interface {
 // Provided by an Adaptor by implementing the I2cConnector interface.
 type Connection I2cOperations
 
+// wrappedConnector is embedded by Connectors wrapping the Connector of
+// an adaptor. It forwards the gobot.Connection methods to the adaptor, so
+// drivers using the wrapper still report the adaptor as their connection.
+type wrappedConnector struct {
+	Connector
+}
+
+// Name returns the name of the wrapped adaptor.
+func (w wrappedConnector) Name() string {
+	if c, ok := w.Connector.(gobot.Connection); ok {
+		return c.Name()
+	}
+	return ""
+}
+
+// SetName sets the name of the wrapped adaptor.
+func (w wrappedConnector) SetName(n string) {
+	if c, ok := w.Connector.(gobot.Connection); ok {
+		c.SetName(n)
+	}
+}
+
+// Connect connects the wrapped adaptor.
+func (w wrappedConnector) Connect() (err error) {
+	if c, ok := w.Connector.(gobot.Connection); ok {
+		return c.Connect()
+	}
+	return
+}
+
+// Finalize finalizes the wrapped adaptor.
+func (w wrappedConnector) Finalize() (err error) {
+	if c, ok := w.Connector.(gobot.Connection); ok {
+		return c.Finalize()
+	}
+	return
+}
+
 type i2cConnection struct {
 	bus     I2cDevice
 	address int
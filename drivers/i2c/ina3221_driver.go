@@ -97,6 +97,9 @@ func (i *INA3221Driver) Start() error {
 	if i.connection, err = i.connector.GetConnection(address, bus); err != nil {
 		return err
 	}
+	if err = CheckCapabilities(i.connector, i.connection, i.name, CapabilityReadWordData|CapabilityWriteBlockData); err != nil {
+		return err
+	}
 
 	if err := i.initialize(); err != nil {
 		return err
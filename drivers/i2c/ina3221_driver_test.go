@@ -54,6 +54,13 @@ func TestINA3221Driver_StartWriteError(t *testing.T) {
 	gobottest.Assert(t, d.Start(), errors.New("write error"))
 }
 
+func TestINA3221DriverStartCapabilityError(t *testing.T) {
+	a := newI2cTestAdaptor()
+	d := NewINA3221Driver(&i2cCapabilityConnector{i2cTestAdaptor: a, capabilities: CapabilityWriteBlockData})
+	err := d.Start()
+	gobottest.Assert(t, err, &CapabilityError{Adaptor: a.Name(), Driver: d.Name(), Missing: CapabilityReadWordData})
+}
+
 func TestINA3221Driver_Halt(t *testing.T) {
 	d := initTestINA3221Driver()
 	gobottest.Assert(t, d.Halt(), nil)
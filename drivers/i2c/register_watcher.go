@@ -0,0 +1,144 @@
+package i2c
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"gobot.io/x/gobot"
+)
+
+const (
+	// RegisterChanged event
+	RegisterChanged = "registerchanged"
+)
+
+var (
+	// ErrRegisterAlreadyWatched is returned when a register is watched twice
+	ErrRegisterAlreadyWatched = errors.New("Register is already watched")
+	// ErrInvalidInterval is returned when a register is watched with an interval of zero or less
+	ErrInvalidInterval = errors.New("Invalid interval")
+)
+
+// RegisterChange is the data of a RegisterChanged event. The values of
+// registers watched with WatchRegister fit into the lower 8 bits.
+type RegisterChange struct {
+	Register uint8
+	Old      uint16
+	New      uint16
+}
+
+// RegisterWatcher polls registers of an i2c device and publishes an event
+// whenever the value of a watched register changes, so that diagnostic tools
+// can observe status registers without their own polling code.
+type RegisterWatcher struct {
+	connection Connection
+	mutex      *sync.Mutex
+	halts      map[uint8]chan bool
+	gobot.Eventer
+}
+
+// NewRegisterWatcher creates a new RegisterWatcher for the device behind
+// the given connection.
+//
+// Emits the Events:
+//	RegisterChanged RegisterChange - a watched register changed its value
+//	Error error - reading a watched register failed
+func NewRegisterWatcher(c Connection) *RegisterWatcher {
+	w := &RegisterWatcher{
+		connection: c,
+		mutex:      &sync.Mutex{},
+		halts:      make(map[uint8]chan bool),
+		Eventer:    gobot.NewEventer(),
+	}
+
+	w.AddEvent(RegisterChanged)
+	w.AddEvent(Error)
+
+	return w
+}
+
+// WatchRegister starts polling the 8-bit register reg at the given interval.
+// The first successful read sets the initial value, every later read which
+// differs from the previous one publishes a RegisterChanged event.
+func (w *RegisterWatcher) WatchRegister(reg uint8, interval time.Duration) (err error) {
+	return w.watch(reg, interval, func() (uint16, error) {
+		val, err := w.connection.ReadByteData(reg)
+		return uint16(val), err
+	})
+}
+
+// WatchWordRegister starts polling the 16-bit register reg at the given
+// interval, like WatchRegister.
+func (w *RegisterWatcher) WatchWordRegister(reg uint8, interval time.Duration) (err error) {
+	return w.watch(reg, interval, func() (uint16, error) {
+		return w.connection.ReadWordData(reg)
+	})
+}
+
+func (w *RegisterWatcher) watch(reg uint8, interval time.Duration, read func() (uint16, error)) (err error) {
+	if interval <= 0 {
+		return ErrInvalidInterval
+	}
+
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	if _, ok := w.halts[reg]; ok {
+		return ErrRegisterAlreadyWatched
+	}
+
+	halt := make(chan bool)
+	w.halts[reg] = halt
+
+	go func() {
+		var value uint16
+		initialized := false
+		timer := time.NewTimer(interval)
+		timer.Stop()
+		for {
+			newValue, err := read()
+			if err != nil {
+				w.Publish(w.Event(Error), err)
+			} else if !initialized {
+				value = newValue
+				initialized = true
+			} else if newValue != value {
+				w.Publish(w.Event(RegisterChanged), RegisterChange{Register: reg, Old: value, New: newValue})
+				value = newValue
+			}
+
+			timer.Reset(interval)
+			select {
+			case <-timer.C:
+			case <-halt:
+				timer.Stop()
+				return
+			}
+		}
+	}()
+	return
+}
+
+// UnwatchRegister stops polling reg.
+func (w *RegisterWatcher) UnwatchRegister(reg uint8) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	if halt, ok := w.halts[reg]; ok {
+		close(halt)
+		delete(w.halts, reg)
+	}
+}
+
+// Halt stops polling all watched registers.
+func (w *RegisterWatcher) Halt() (err error) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	for reg, halt := range w.halts {
+		close(halt)
+		delete(w.halts, reg)
+	}
+	return
+}
@@ -0,0 +1,123 @@
+package i2c
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"gobot.io/x/gobot"
+	"gobot.io/x/gobot/gobottest"
+)
+
+var _ gobot.Eventer = (*RegisterWatcher)(nil)
+
+// initTestRegisterWatcherAdaptor returns an adaptor reading value, and a
+// channel closed once the first read returned.
+func initTestRegisterWatcherAdaptor(value *int32) (*i2cTestAdaptor, chan bool) {
+	a := newI2cTestAdaptor()
+	read := make(chan bool)
+	var once sync.Once
+	a.i2cReadImpl = func(b []byte) (int, error) {
+		v := atomic.LoadInt32(value)
+		for i := range b {
+			b[i] = byte(v >> (8 * uint(i)))
+		}
+		once.Do(func() { close(read) })
+		return len(b), nil
+	}
+	return a, read
+}
+
+func TestRegisterWatcherRegisterChanged(t *testing.T) {
+	var value int32
+	a, read := initTestRegisterWatcherAdaptor(&value)
+
+	w := NewRegisterWatcher(a)
+	defer w.Halt()
+
+	sem := make(chan RegisterChange, 1)
+	w.Once(w.Event(RegisterChanged), func(data interface{}) {
+		sem <- data.(RegisterChange)
+	})
+
+	gobottest.Assert(t, w.WatchRegister(0x12, time.Millisecond), nil)
+	<-read
+	atomic.StoreInt32(&value, 0x04)
+
+	select {
+	case change := <-sem:
+		gobottest.Assert(t, change, RegisterChange{Register: 0x12, Old: 0x00, New: 0x04})
+	case <-time.After(1 * time.Second):
+		t.Errorf("RegisterChanged was not published")
+	}
+}
+
+func TestRegisterWatcherWordRegisterChanged(t *testing.T) {
+	value := int32(0x0100)
+	a, read := initTestRegisterWatcherAdaptor(&value)
+
+	w := NewRegisterWatcher(a)
+	defer w.Halt()
+
+	sem := make(chan RegisterChange, 1)
+	w.Once(w.Event(RegisterChanged), func(data interface{}) {
+		sem <- data.(RegisterChange)
+	})
+
+	gobottest.Assert(t, w.WatchWordRegister(0x12, time.Millisecond), nil)
+	<-read
+	atomic.StoreInt32(&value, 0x1234)
+
+	select {
+	case change := <-sem:
+		gobottest.Assert(t, change, RegisterChange{Register: 0x12, Old: 0x0100, New: 0x1234})
+	case <-time.After(1 * time.Second):
+		t.Errorf("RegisterChanged was not published")
+	}
+}
+
+func TestRegisterWatcherInvalidInterval(t *testing.T) {
+	w := NewRegisterWatcher(newI2cTestAdaptor())
+	defer w.Halt()
+
+	gobottest.Assert(t, w.WatchRegister(0x12, 0), ErrInvalidInterval)
+	gobottest.Assert(t, w.WatchWordRegister(0x12, -time.Millisecond), ErrInvalidInterval)
+	gobottest.Assert(t, w.WatchRegister(0x12, time.Millisecond), nil)
+}
+
+func TestRegisterWatcherError(t *testing.T) {
+	a := newI2cTestAdaptor()
+	a.i2cReadImpl = func(b []byte) (int, error) {
+		return 0, errors.New("read error")
+	}
+
+	w := NewRegisterWatcher(a)
+	defer w.Halt()
+
+	sem := make(chan error, 1)
+	w.Once(w.Event(Error), func(data interface{}) {
+		sem <- data.(error)
+	})
+
+	gobottest.Assert(t, w.WatchRegister(0x12, time.Millisecond), nil)
+
+	select {
+	case err := <-sem:
+		gobottest.Assert(t, err, errors.New("read error"))
+	case <-time.After(1 * time.Second):
+		t.Errorf("Error was not published")
+	}
+}
+
+func TestRegisterWatcherWatchTwice(t *testing.T) {
+	w := NewRegisterWatcher(newI2cTestAdaptor())
+	defer w.Halt()
+
+	gobottest.Assert(t, w.WatchRegister(0x12, time.Millisecond), nil)
+	gobottest.Assert(t, w.WatchRegister(0x12, time.Millisecond), ErrRegisterAlreadyWatched)
+
+	w.UnwatchRegister(0x12)
+	gobottest.Assert(t, w.WatchRegister(0x12, time.Millisecond), nil)
+}
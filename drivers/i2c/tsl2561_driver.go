@@ -237,6 +237,9 @@ func (d *TSL2561Driver) Start() (err error) {
 	if d.connection, err = d.connector.GetConnection(address, bus); err != nil {
 		return err
 	}
+	if err = CheckCapabilities(d.connector, d.connection, d.name, CapabilityReadByteData|CapabilityWriteByteData|CapabilityReadWordData); err != nil {
+		return err
+	}
 
 	if err = d.enable(); err != nil {
 		return err
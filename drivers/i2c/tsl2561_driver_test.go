@@ -40,6 +40,13 @@ func TestTSL2561StartConnectError(t *testing.T) {
 	gobottest.Assert(t, d.Start(), errors.New("Invalid i2c connection"))
 }
 
+func TestTSL2561DriverStartCapabilityError(t *testing.T) {
+	a := newI2cTestAdaptor()
+	d := NewTSL2561Driver(&i2cCapabilityConnector{i2cTestAdaptor: a, capabilities: CapabilityReadByteData | CapabilityWriteByteData})
+	err := d.Start()
+	gobottest.Assert(t, err, &CapabilityError{Adaptor: a.Name(), Driver: d.Name(), Missing: CapabilityReadWordData})
+}
+
 func TestTSL2561DriverStartError(t *testing.T) {
 	d, adaptor := initTestTSL2561Driver()
 	adaptor.i2cWriteImpl = func([]byte) (int, error) {
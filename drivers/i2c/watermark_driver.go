@@ -0,0 +1,249 @@
+package i2c
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"gobot.io/x/gobot"
+)
+
+// ErrWatermarkGenesis is the error returned by VerifyWatermarks when the
+// first sample of a log does not start the chain.
+var ErrWatermarkGenesis = errors.New("first sample does not start the chain")
+
+// WatermarkedSample is a sample carrying the hash of the previous sample of
+// the same device, so a log of samples can be checked for samples altered,
+// reordered or dropped after capture.
+type WatermarkedSample struct {
+	Device   string      `json:"device"`
+	Sequence uint64      `json:"sequence"`
+	Time     time.Time   `json:"time"`
+	Value    interface{} `json:"value"`
+	PrevHash string      `json:"prev_hash"`
+	Hash     string      `json:"hash"`
+}
+
+// hash returns the SHA-256 of the sample without its own hash, or its
+// HMAC-SHA256 when a key is given.
+func (s WatermarkedSample) hash(key []byte) (string, error) {
+	s.Hash = ""
+	b, err := json.Marshal(s)
+	if err != nil {
+		return "", err
+	}
+
+	if key == nil {
+		sum := sha256.Sum256(b)
+		return hex.EncodeToString(sum[:]), nil
+	}
+	mac := hmac.New(sha256.New, key)
+	mac.Write(b)
+	return hex.EncodeToString(mac.Sum(nil)), nil
+}
+
+// WatermarkDriver republishes the samples of a device as chain-hashed
+// WatermarkedSamples for tamper-evident logging. The first sample starts
+// the chain with an empty PrevHash.
+//
+// Without a key the chain only detects accidental or careless changes;
+// anyone editing a log can recompute all later hashes. Set a key kept away
+// from the logs to make the chain tamper-evident. Dropping samples from the
+// end of a log can not be detected from the log alone, store the Head
+// somewhere else and compare it with the last sample. Restore the stored
+// Head with SetHead to continue the chain after a restart.
+//
+// Samples are timestamped with the Clock component of the robot, see
+// gobot.ClockComponent, or with the system time.
+type WatermarkDriver struct {
+	name     string
+	key      []byte
+	clock    gobot.Clock
+	source   gobot.Eventer
+	event    string
+	mutex    *sync.Mutex
+	sequence uint64
+	prevHash string
+	halt     chan bool
+	gobot.Eventer
+}
+
+// NewWatermarkDriver returns a new WatermarkDriver for the data of the event
+// published by source. The samples carry the name of the source as Device
+// when the source has a name, like drivers do, and the name of the
+// WatermarkDriver otherwise.
+func NewWatermarkDriver(source gobot.Eventer, event string) *WatermarkDriver {
+	w := &WatermarkDriver{
+		name:    gobot.DefaultName("Watermark"),
+		source:  source,
+		event:   event,
+		clock:   gobot.SystemClock{},
+		mutex:   &sync.Mutex{},
+		Eventer: gobot.NewEventer(),
+	}
+
+	w.AddEvent(Data)
+	w.AddEvent(Error)
+
+	return w
+}
+
+// Name returns the WatermarkDrivers name
+func (w *WatermarkDriver) Name() string { return w.name }
+
+// SetName sets the WatermarkDrivers name
+func (w *WatermarkDriver) SetName(n string) { w.name = n }
+
+// Connection returns nil, a watermark has no connection
+func (w *WatermarkDriver) Connection() gobot.Connection { return nil }
+
+// UseComponents uses the Clock registered as gobot.ClockComponent to
+// timestamp the samples.
+func (w *WatermarkDriver) UseComponents(c *gobot.Components) error {
+	clock, err := c.Clock()
+	if err != nil {
+		return err
+	}
+
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	w.clock = clock
+	return nil
+}
+
+// SetKey sets the key the samples are signed with using HMAC-SHA256. It
+// has to be set before Start, the same key verifies the log.
+func (w *WatermarkDriver) SetKey(key []byte) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	w.key = key
+}
+
+// Head returns the sequence number and hash of the last watermarked sample.
+// ok is false before the first sample.
+func (w *WatermarkDriver) Head() (sequence uint64, hash string, ok bool) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	if w.sequence == 0 {
+		return 0, "", false
+	}
+	return w.sequence - 1, w.prevHash, true
+}
+
+// SetHead continues the chain after the sample with the given sequence
+// number and hash, as returned by Head before a restart. The next sample
+// gets the sequence number sequence+1.
+func (w *WatermarkDriver) SetHead(sequence uint64, hash string) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	w.sequence = sequence + 1
+	w.prevHash = hash
+}
+
+// Start starts watermarking the samples of the source.
+// Emits the Events:
+//	Data WatermarkedSample - a sample of the source
+//	Error error - a sample could not be hashed
+func (w *WatermarkDriver) Start() (err error) {
+	halt := make(chan bool)
+	w.halt = halt
+
+	out := w.source.Subscribe()
+	go func() {
+		for {
+			select {
+			case evt := <-out:
+				if evt.Name != w.event {
+					continue
+				}
+				if s, err := w.watermark(evt.Data, w.now()); err != nil {
+					w.Publish(w.Event(Error), err)
+				} else {
+					w.Publish(w.Event(Data), s)
+				}
+			case <-halt:
+				gobot.UnsubscribeAndDrain(w.source, out)
+				return
+			}
+		}
+	}()
+	return
+}
+
+// Halt stops watermarking.
+func (w *WatermarkDriver) Halt() (err error) {
+	if w.halt != nil {
+		close(w.halt)
+		w.halt = nil
+	}
+	return
+}
+
+func (w *WatermarkDriver) now() time.Time {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	return w.clock.Now()
+}
+
+func (w *WatermarkDriver) watermark(value interface{}, t time.Time) (s WatermarkedSample, err error) {
+	device := w.name
+	if named, ok := w.source.(interface{ Name() string }); ok && named.Name() != "" {
+		device = named.Name()
+	}
+
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	s = WatermarkedSample{
+		Device:   device,
+		Sequence: w.sequence,
+		Time:     t,
+		Value:    value,
+		PrevHash: w.prevHash,
+	}
+	if s.Hash, err = s.hash(w.key); err != nil {
+		return s, err
+	}
+
+	w.sequence++
+	w.prevHash = s.Hash
+	return s, nil
+}
+
+// VerifyWatermarks checks that samples is an unaltered chain of
+// WatermarkedSamples of a single device, signed with key or unkeyed when
+// key is nil. The log may start anywhere in the chain when partial is true,
+// otherwise it has to start with the first sample. Samples missing at the
+// end are only detected by comparing the last sample with the stored Head.
+func VerifyWatermarks(samples []WatermarkedSample, key []byte, partial bool) error {
+	for i, s := range samples {
+		if i == 0 && !partial && (s.Sequence != 0 || s.PrevHash != "") {
+			return ErrWatermarkGenesis
+		}
+		if i > 0 {
+			prev := samples[i-1]
+			if s.Device != prev.Device || s.Sequence != prev.Sequence+1 || s.PrevHash != prev.Hash {
+				return fmt.Errorf("sample %d does not follow sample %d", s.Sequence, prev.Sequence)
+			}
+		}
+
+		hash, err := s.hash(key)
+		if err != nil {
+			return err
+		}
+		if hash != s.Hash {
+			return fmt.Errorf("sample %d has been altered", s.Sequence)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,191 @@
+package i2c
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"gobot.io/x/gobot"
+	"gobot.io/x/gobot/gobottest"
+)
+
+var _ gobot.Driver = (*WatermarkDriver)(nil)
+var _ gobot.ComponentUser = (*WatermarkDriver)(nil)
+
+type watermarkTestClock struct{ now time.Time }
+
+func (c watermarkTestClock) Now() time.Time { return c.now }
+
+// watermarkTestSource is an event source with a name, like a driver.
+type watermarkTestSource struct {
+	gobot.Eventer
+	name string
+}
+
+func (s *watermarkTestSource) Name() string { return s.name }
+
+func initTestWatermarks(values ...interface{}) []WatermarkedSample {
+	return initTestKeyedWatermarks(nil, values...)
+}
+
+func initTestKeyedWatermarks(key []byte, values ...interface{}) []WatermarkedSample {
+	w := NewWatermarkDriver(gobot.NewEventer(), "data")
+	w.SetName("thermometer")
+	w.SetKey(key)
+	samples := []WatermarkedSample{}
+	for i, v := range values {
+		s, _ := w.watermark(v, time.Unix(int64(i), 0))
+		samples = append(samples, s)
+	}
+	return samples
+}
+
+func TestNewWatermarkDriver(t *testing.T) {
+	w := NewWatermarkDriver(gobot.NewEventer(), "data")
+	gobottest.Assert(t, w.Name()[:9], "Watermark")
+	gobottest.Assert(t, w.Connection(), nil)
+}
+
+func TestWatermarkDriverDevice(t *testing.T) {
+	sensor := &watermarkTestSource{Eventer: gobot.NewEventer()}
+	w := NewWatermarkDriver(sensor, "data")
+	// the name is read when signing, drivers are often named after creation
+	sensor.name = "thermometer"
+	s, _ := w.watermark(21.5, time.Now())
+	gobottest.Assert(t, s.Device, "thermometer")
+
+	w = NewWatermarkDriver(gobot.NewEventer(), "data")
+	s, _ = w.watermark(21.5, time.Now())
+	gobottest.Assert(t, s.Device, w.Name())
+}
+
+func TestWatermarkDriverHead(t *testing.T) {
+	w := NewWatermarkDriver(gobot.NewEventer(), "data")
+	_, _, ok := w.Head()
+	gobottest.Assert(t, ok, false)
+
+	w.watermark(21.5, time.Now())
+	s, _ := w.watermark(21.6, time.Now())
+	sequence, hash, ok := w.Head()
+	gobottest.Assert(t, ok, true)
+	gobottest.Assert(t, sequence, uint64(1))
+	gobottest.Assert(t, hash, s.Hash)
+}
+
+func TestWatermarkDriverSetHead(t *testing.T) {
+	key := []byte("secret")
+	samples := initTestKeyedWatermarks(key, 21.5, 21.6)
+	last := samples[len(samples)-1]
+
+	// a new driver, e.g. after a restart, continues the chain
+	w := NewWatermarkDriver(gobot.NewEventer(), "data")
+	w.SetName("thermometer")
+	w.SetKey(key)
+	w.SetHead(last.Sequence, last.Hash)
+	sequence, hash, ok := w.Head()
+	gobottest.Assert(t, ok, true)
+	gobottest.Assert(t, sequence, last.Sequence)
+	gobottest.Assert(t, hash, last.Hash)
+
+	s, err := w.watermark(21.4, time.Unix(2, 0))
+	gobottest.Assert(t, err, nil)
+	gobottest.Assert(t, s.Sequence, uint64(2))
+	gobottest.Assert(t, s.PrevHash, last.Hash)
+	gobottest.Assert(t, VerifyWatermarks(append(samples, s), key, false), nil)
+}
+
+func TestWatermarkDriverKey(t *testing.T) {
+	key := []byte("secret")
+	samples := initTestKeyedWatermarks(key, 21.5, 21.6, 21.4)
+	gobottest.Assert(t, VerifyWatermarks(samples, key, false), nil)
+	gobottest.Assert(t, VerifyWatermarks(samples, []byte("other"), false).Error(), "sample 0 has been altered")
+	gobottest.Assert(t, VerifyWatermarks(samples, nil, false).Error(), "sample 0 has been altered")
+
+	// a forger without the key can not recompute a valid chain
+	forged := initTestWatermarks(21.5, 21.6, 21.4)
+	gobottest.Assert(t, VerifyWatermarks(forged, key, false).Error(), "sample 0 has been altered")
+}
+
+func TestWatermarkDriverChain(t *testing.T) {
+	samples := initTestWatermarks(21.5, 21.6, 21.4)
+
+	gobottest.Assert(t, samples[0].Sequence, uint64(0))
+	gobottest.Assert(t, samples[0].PrevHash, "")
+	gobottest.Assert(t, samples[1].PrevHash, samples[0].Hash)
+	gobottest.Assert(t, samples[2].PrevHash, samples[1].Hash)
+	gobottest.Assert(t, VerifyWatermarks(samples, nil, false), nil)
+	gobottest.Assert(t, VerifyWatermarks(samples[1:], nil, true), nil)
+	gobottest.Assert(t, VerifyWatermarks(samples[1:], nil, false), ErrWatermarkGenesis)
+}
+
+func TestVerifyWatermarksJSON(t *testing.T) {
+	b, _ := json.Marshal(initTestWatermarks(21, 21.6, "open"))
+	samples := []WatermarkedSample{}
+	gobottest.Assert(t, json.Unmarshal(b, &samples), nil)
+	gobottest.Assert(t, VerifyWatermarks(samples, nil, false), nil)
+}
+
+func TestVerifyWatermarksTampered(t *testing.T) {
+	samples := initTestWatermarks(21.5, 21.6, 21.4)
+	samples[1].Value = 20.0
+	gobottest.Assert(t, VerifyWatermarks(samples, nil, false).Error(), "sample 1 has been altered")
+
+	samples = initTestWatermarks(21.5, 21.6, 21.4)
+	samples = append(samples[:1], samples[2:]...)
+	gobottest.Assert(t, VerifyWatermarks(samples, nil, false).Error(), "sample 2 does not follow sample 0")
+}
+
+func TestWatermarkDriverStart(t *testing.T) {
+	source := gobot.NewEventer()
+	source.AddEvent("data")
+	w := NewWatermarkDriver(source, "data")
+	gobottest.Assert(t, w.Start(), nil)
+	defer w.Halt()
+
+	sem := make(chan interface{}, 1)
+	w.Once(w.Event(Data), func(data interface{}) {
+		sem <- data
+	})
+
+	source.Publish("data", 21.5)
+	select {
+	case data := <-sem:
+		s := data.(WatermarkedSample)
+		gobottest.Assert(t, s.Value, 21.5)
+		gobottest.Assert(t, VerifyWatermarks([]WatermarkedSample{s}, nil, false), nil)
+	case <-time.After(time.Second):
+		t.Errorf("Data was not published")
+	}
+}
+
+func TestWatermarkDriverUseComponents(t *testing.T) {
+	source := gobot.NewEventer()
+	source.AddEvent("data")
+	w := NewWatermarkDriver(source, "data")
+
+	r := gobot.NewRobot("watermarks", []gobot.Device{w})
+	r.AutoRun = false
+	r.Components().Register(gobot.ClockComponent, watermarkTestClock{now: time.Unix(42, 0)})
+	gobottest.Assert(t, r.Start(false), nil)
+	defer r.Stop()
+
+	sem := make(chan interface{}, 1)
+	w.Once(w.Event(Data), func(data interface{}) {
+		sem <- data
+	})
+
+	source.Publish("data", 21.5)
+	select {
+	case data := <-sem:
+		gobottest.Assert(t, data.(WatermarkedSample).Time, time.Unix(42, 0))
+	case <-time.After(time.Second):
+		t.Errorf("Data was not published")
+	}
+}
+
+func TestWatermarkDriverUseComponentsError(t *testing.T) {
+	c := gobot.NewComponents(nil)
+	c.Register(gobot.ClockComponent, "noon")
+	w := NewWatermarkDriver(gobot.NewEventer(), "data")
+	gobottest.Refute(t, w.UseComponents(c), nil)
+}
@@ -0,0 +1,349 @@
+package spi
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"gobot.io/x/gobot"
+	"gobot.io/x/gobot/drivers/i2c"
+)
+
+const (
+	sc18is600Write         = 0x00
+	sc18is600Read          = 0x01
+	sc18is600ReadAfterWr   = 0x02
+	sc18is600ReadBuffer    = 0x06
+	sc18is600WriteRegister = 0x20
+	sc18is600ReadRegister  = 0x21
+
+	// internal registers
+	sc18is600RegI2CClk  = 0x02
+	sc18is600RegI2CStat = 0x04
+
+	// I2CStat values
+	sc18is600StatSuccess      = 0xF0
+	sc18is600StatAddressNack  = 0xF1
+	sc18is600StatDataNack     = 0xF2
+	sc18is600StatBusy         = 0xF3
+	sc18is600StatTimeout      = 0xF8
+	sc18is600StatInvalidCount = 0xF9
+
+	// sc18is600BufferSize is the size of the data buffer of the bridge
+	sc18is600BufferSize = 96
+
+	// sc18is600Oscillator is the frequency of the internal oscillator
+	sc18is600Oscillator = 7372800
+
+	// sc18is600StatusRetries is the number of times I2CStat is polled
+	// while a transfer is in progress
+	sc18is600StatusRetries = 10
+
+	// sc18is600SpiMode is the SPI mode required by the bridge
+	sc18is600SpiMode = 3
+)
+
+const (
+	// SC18IS600Clock369kHz sets the I2C clock of the bridge to 369 kHz
+	SC18IS600Clock369kHz = 0x05
+	// SC18IS600Clock97kHz sets the I2C clock of the bridge to 97 kHz
+	SC18IS600Clock97kHz = 0x13
+	// SC18IS600Clock58kHz sets the I2C clock of the bridge to 58 kHz
+	SC18IS600Clock58kHz = 0x20
+	// SC18IS600Clock7kHz sets the I2C clock of the bridge to 7.2 kHz
+	SC18IS600Clock7kHz = 0xFF
+)
+
+var (
+	// ErrSC18IS600BufferSize is returned for transfers exceeding the bridge buffer
+	ErrSC18IS600BufferSize = errors.New("Transfer exceeds the 96 bytes buffer of the SC18IS600")
+	// ErrSC18IS600AddressNack is returned when no device acknowledges the address
+	ErrSC18IS600AddressNack = errors.New("I2C device did not acknowledge its address")
+	// ErrSC18IS600DataNack is returned when the device does not acknowledge data
+	ErrSC18IS600DataNack = errors.New("I2C device did not acknowledge data")
+	// ErrSC18IS600Busy is returned when a transfer does not complete
+	ErrSC18IS600Busy = errors.New("I2C bus of the SC18IS600 is busy")
+	// ErrSC18IS600Timeout is returned when the bridge reports an I2C bus time-out
+	ErrSC18IS600Timeout = errors.New("I2C bus of the SC18IS600 timed out")
+	// ErrSC18IS600NotStarted is returned for transfers before the bridge is started
+	ErrSC18IS600NotStarted = errors.New("SC18IS600 is not started")
+)
+
+// SC18IS600Driver is a driver for the SC18IS600/601 SPI to I2C-bus bridge.
+// It implements the i2c.Connector interface, so i2c drivers can be used
+// with devices behind the bridge:
+//
+//		bridge := spi.NewSC18IS600Driver(adaptor)
+//		sensor := i2c.NewBMP180Driver(bridge)
+//
+// The bridge uses SPI mode 3 at up to 1.2 MHz, mode 3 is used unless
+// another one is set with spi.WithMode. The bridge must be started before
+// the i2c drivers using it. After every transfer the
+// I2CStat register is read, so missing devices and NACKs are returned as
+// errors. The I2C clock constants assume the 7.3728 MHz internal oscillator
+// of the SC18IS600; with an SC18IS601 the clock scales with its external
+// clock.
+//
+// Datasheet:
+// https://www.nxp.com/docs/en/data-sheet/SC18IS600_601.pdf
+type SC18IS600Driver struct {
+	name       string
+	connector  Connector
+	connection Connection
+	mutex      *sync.Mutex
+	clock      byte
+	Config
+}
+
+// NewSC18IS600Driver creates a new driver for the SC18IS600 bridge.
+//
+// Params:
+//      a *Adaptor - the Adaptor to use with this Driver
+//
+// Optional params:
+//      spi.WithBus(int):    	bus to use with this driver
+//     	spi.WithChip(int):    	chip to use with this driver
+//      spi.WithMode(int):    	mode to use with this driver, 3 by default
+//      spi.WithBits(int):    	number of bits to use with this driver
+//      spi.WithSpeed(int64):   speed in Hz to use with this driver
+//
+func NewSC18IS600Driver(a Connector, options ...func(Config)) *SC18IS600Driver {
+	d := &SC18IS600Driver{
+		name:      gobot.DefaultName("SC18IS600"),
+		connector: a,
+		mutex:     &sync.Mutex{},
+		clock:     SC18IS600Clock97kHz,
+		Config:    NewConfig(),
+	}
+	for _, option := range options {
+		option(d)
+	}
+	return d
+}
+
+// Name returns the name of the device.
+func (d *SC18IS600Driver) Name() string { return d.name }
+
+// SetName sets the name of the device.
+func (d *SC18IS600Driver) SetName(n string) { d.name = n }
+
+// Connection returns the Connection of the device.
+func (d *SC18IS600Driver) Connection() gobot.Connection { return d.connection.(gobot.Connection) }
+
+// Start initializes the driver and sets the I2C clock of the bridge.
+func (d *SC18IS600Driver) Start() (err error) {
+	bus := d.GetBusOrDefault(d.connector.GetSpiDefaultBus())
+	chip := d.GetChipOrDefault(d.connector.GetSpiDefaultChip())
+	mode := d.GetModeOrDefault(sc18is600SpiMode)
+	bits := d.GetBitsOrDefault(d.connector.GetSpiDefaultBits())
+	maxSpeed := d.GetSpeedOrDefault(d.connector.GetSpiDefaultMaxSpeed())
+
+	d.connection, err = d.connector.GetSpiConnection(bus, chip, mode, bits, maxSpeed)
+	if err != nil {
+		return err
+	}
+	return d.SetI2CClock(d.clock)
+}
+
+// Halt stops the driver.
+func (d *SC18IS600Driver) Halt() (err error) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	if d.connection == nil {
+		return
+	}
+	err = d.connection.Close()
+	d.connection = nil
+	return
+}
+
+// SetI2CClock sets the divider of the I2C clock of the bridge, which runs
+// at 7.3728 MHz / (4 * clock). Dividers below 5 are invalid, the
+// SC18IS600Clock constants hold common values. It can be called before Start.
+func (d *SC18IS600Driver) SetI2CClock(clock byte) (err error) {
+	if clock < SC18IS600Clock369kHz {
+		return errors.New("Invalid I2C clock divider for SC18IS600")
+	}
+
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	d.clock = clock
+	if d.connection == nil {
+		return nil
+	}
+	return d.connection.Tx([]byte{sc18is600WriteRegister, sc18is600RegI2CClk, clock}, nil)
+}
+
+// GetConnection returns a connection to the i2c device at address behind
+// the bridge. The bridge has a single bus, so bus is ignored.
+func (d *SC18IS600Driver) GetConnection(address int, bus int) (connection i2c.Connection, err error) {
+	return &sc18is600Connection{bridge: d, address: byte(address)}, nil
+}
+
+// GetDefaultBus returns the default i2c bus of the bridge.
+func (d *SC18IS600Driver) GetDefaultBus() int {
+	return 0
+}
+
+// write writes data to the i2c device at address.
+func (d *SC18IS600Driver) write(address byte, data []byte) (err error) {
+	if len(data) > sc18is600BufferSize {
+		return ErrSC18IS600BufferSize
+	}
+
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	if d.connection == nil {
+		return ErrSC18IS600NotStarted
+	}
+	cmd := append([]byte{sc18is600Write, byte(len(data)), address << 1}, data...)
+	if err = d.connection.Tx(cmd, nil); err != nil {
+		return
+	}
+	return d.waitTransfer(len(data))
+}
+
+// read reads len(data) bytes from the i2c device at address, optionally
+// writing w first with a repeated start.
+func (d *SC18IS600Driver) read(address byte, w []byte, data []byte) (err error) {
+	if len(w) > sc18is600BufferSize || len(data) > sc18is600BufferSize {
+		return ErrSC18IS600BufferSize
+	}
+
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	if d.connection == nil {
+		return ErrSC18IS600NotStarted
+	}
+	var cmd []byte
+	if len(w) == 0 {
+		cmd = []byte{sc18is600Read, byte(len(data)), address<<1 | 0x01}
+	} else {
+		cmd = append([]byte{sc18is600ReadAfterWr, byte(len(w)), byte(len(data)), address << 1}, w...)
+		cmd = append(cmd, address<<1|0x01)
+	}
+	if err = d.connection.Tx(cmd, nil); err != nil {
+		return
+	}
+	if err = d.waitTransfer(len(w) + len(data)); err != nil {
+		return
+	}
+
+	// the buffer is shifted out while the dummy bytes after the command are sent
+	buf := make([]byte, len(data)+1)
+	tx := make([]byte, len(data)+1)
+	tx[0] = sc18is600ReadBuffer
+	if err = d.connection.Tx(tx, buf); err != nil {
+		return
+	}
+	copy(data, buf[1:])
+	return
+}
+
+// waitTransfer waits until the bridge has transferred n data bytes on the
+// i2c bus and returns the error reported in I2CStat.
+func (d *SC18IS600Driver) waitTransfer(n int) error {
+	bits := (n + 2) * 9
+	hz := sc18is600Oscillator / (4 * int(d.clock))
+	wait := time.Duration(bits)*time.Second/time.Duration(hz) + 100*time.Microsecond
+
+	for i := 0; i < sc18is600StatusRetries; i++ {
+		time.Sleep(wait)
+
+		buf := make([]byte, 3)
+		if err := d.connection.Tx([]byte{sc18is600ReadRegister, sc18is600RegI2CStat, 0x00}, buf); err != nil {
+			return err
+		}
+
+		switch buf[2] {
+		case sc18is600StatSuccess:
+			return nil
+		case sc18is600StatBusy:
+			continue
+		case sc18is600StatAddressNack:
+			return ErrSC18IS600AddressNack
+		case sc18is600StatDataNack:
+			return ErrSC18IS600DataNack
+		case sc18is600StatTimeout:
+			return ErrSC18IS600Timeout
+		case sc18is600StatInvalidCount:
+			return ErrSC18IS600BufferSize
+		default:
+			return fmt.Errorf("Unknown SC18IS600 I2C status 0x%02X", buf[2])
+		}
+	}
+	return ErrSC18IS600Busy
+}
+
+// sc18is600Connection is an i2c.Connection to a device behind the bridge.
+type sc18is600Connection struct {
+	bridge  *SC18IS600Driver
+	address byte
+}
+
+// Read data from the i2c device.
+func (c *sc18is600Connection) Read(data []byte) (n int, err error) {
+	if err = c.bridge.read(c.address, nil, data); err != nil {
+		return 0, err
+	}
+	return len(data), nil
+}
+
+// Write data to the i2c device.
+func (c *sc18is600Connection) Write(data []byte) (n int, err error) {
+	if err = c.bridge.write(c.address, data); err != nil {
+		return 0, err
+	}
+	return len(data), nil
+}
+
+// Close the connection. The bridge itself is closed when it is halted.
+func (c *sc18is600Connection) Close() error {
+	return nil
+}
+
+// ReadByte reads a single byte from the i2c device.
+func (c *sc18is600Connection) ReadByte() (val byte, err error) {
+	buf := []byte{0}
+	err = c.bridge.read(c.address, nil, buf)
+	return buf[0], err
+}
+
+// ReadByteData reads a byte value for a register on the i2c device.
+func (c *sc18is600Connection) ReadByteData(reg uint8) (val uint8, err error) {
+	buf := []byte{0}
+	err = c.bridge.read(c.address, []byte{reg}, buf)
+	return buf[0], err
+}
+
+// ReadWordData reads a word value for a register on the i2c device.
+func (c *sc18is600Connection) ReadWordData(reg uint8) (val uint16, err error) {
+	buf := []byte{0, 0}
+	err = c.bridge.read(c.address, []byte{reg}, buf)
+	return uint16(buf[1])<<8 | uint16(buf[0]), err
+}
+
+// WriteByte writes a single byte to the i2c device.
+func (c *sc18is600Connection) WriteByte(val byte) (err error) {
+	return c.bridge.write(c.address, []byte{val})
+}
+
+// WriteByteData writes a byte value to a register on the i2c device.
+func (c *sc18is600Connection) WriteByteData(reg uint8, val uint8) (err error) {
+	return c.bridge.write(c.address, []byte{reg, val})
+}
+
+// WriteWordData writes a word value to a register on the i2c device.
+func (c *sc18is600Connection) WriteWordData(reg uint8, val uint16) (err error) {
+	return c.bridge.write(c.address, []byte{reg, byte(val), byte(val >> 8)})
+}
+
+// WriteBlockData writes a block of bytes to a register on the i2c device.
+func (c *sc18is600Connection) WriteBlockData(reg uint8, b []byte) (err error) {
+	return c.bridge.write(c.address, append([]byte{reg}, b...))
+}
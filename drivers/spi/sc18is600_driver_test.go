@@ -0,0 +1,189 @@
+package spi
+
+import (
+	"errors"
+	"testing"
+
+	"gobot.io/x/gobot"
+	"gobot.io/x/gobot/drivers/i2c"
+	"gobot.io/x/gobot/gobottest"
+)
+
+var _ gobot.Driver = (*SC18IS600Driver)(nil)
+
+// must implement the i2c Connector interface
+var _ i2c.Connector = (*SC18IS600Driver)(nil)
+
+// sc18is600TestConnection simulates the bridge. Reads of I2CStat return
+// the queued status values, then success, and the read buffer command
+// shifts out buffer.
+type sc18is600TestConnection struct {
+	tx     [][]byte
+	status []byte
+	buffer []byte
+	err    error
+}
+
+func (c *sc18is600TestConnection) Close() error { return nil }
+
+func (c *sc18is600TestConnection) Tx(w, r []byte) error {
+	c.tx = append(c.tx, append([]byte{}, w...))
+	switch {
+	case w[0] == sc18is600ReadRegister && w[1] == sc18is600RegI2CStat:
+		r[2] = sc18is600StatSuccess
+		if len(c.status) > 0 {
+			r[2], c.status = c.status[0], c.status[1:]
+		}
+	case w[0] == sc18is600ReadBuffer:
+		copy(r[1:], c.buffer)
+	}
+	return c.err
+}
+
+type sc18is600TestConnector struct {
+	TestConnector
+	connection *sc18is600TestConnection
+	mode       int
+}
+
+func (ctr *sc18is600TestConnector) GetSpiConnection(busNum, chipNum, mode, bits int, maxSpeed int64) (device Connection, err error) {
+	ctr.mode = mode
+	return ctr.connection, nil
+}
+
+var sc18is600ReadStatus = []byte{0x21, 0x04, 0x00}
+
+func initTestSC18IS600Driver() (*SC18IS600Driver, *sc18is600TestConnection) {
+	c := &sc18is600TestConnection{}
+	d := NewSC18IS600Driver(&sc18is600TestConnector{connection: c})
+	d.SetI2CClock(SC18IS600Clock369kHz)
+	d.Start()
+	c.tx = nil
+	return d, c
+}
+
+func TestSC18IS600DriverStart(t *testing.T) {
+	c := &sc18is600TestConnection{}
+	ctr := &sc18is600TestConnector{connection: c}
+	d := NewSC18IS600Driver(ctr)
+	gobottest.Assert(t, d.Start(), nil)
+	gobottest.Assert(t, c.tx, [][]byte{{0x20, 0x02, 0x13}})
+	gobottest.Assert(t, ctr.mode, 3)
+}
+
+func TestSC18IS600DriverStartMode(t *testing.T) {
+	ctr := &sc18is600TestConnector{connection: &sc18is600TestConnection{}}
+	d := NewSC18IS600Driver(ctr, WithMode(0))
+	gobottest.Assert(t, d.Start(), nil)
+	gobottest.Assert(t, ctr.mode, 0)
+}
+
+func TestSC18IS600DriverHalt(t *testing.T) {
+	d, _ := initTestSC18IS600Driver()
+	gobottest.Assert(t, d.Halt(), nil)
+	gobottest.Assert(t, d.Halt(), nil)
+}
+
+func TestSC18IS600DriverNotStarted(t *testing.T) {
+	d := NewSC18IS600Driver(&sc18is600TestConnector{connection: &sc18is600TestConnection{}})
+	conn, err := d.GetConnection(0x40, d.GetDefaultBus())
+	gobottest.Assert(t, err, nil)
+	gobottest.Assert(t, conn.WriteByteData(0x01, 0x02), ErrSC18IS600NotStarted)
+	_, err = conn.ReadByteData(0x01)
+	gobottest.Assert(t, err, ErrSC18IS600NotStarted)
+
+	d.Start()
+	d.Halt()
+	_, err = conn.Read(make([]byte, 1))
+	gobottest.Assert(t, err, ErrSC18IS600NotStarted)
+}
+
+func TestSC18IS600DriverSetI2CClock(t *testing.T) {
+	d, c := initTestSC18IS600Driver()
+	gobottest.Assert(t, d.SetI2CClock(SC18IS600Clock7kHz), nil)
+	gobottest.Assert(t, c.tx, [][]byte{{0x20, 0x02, 0xFF}})
+	gobottest.Assert(t, d.SetI2CClock(0x04), errors.New("Invalid I2C clock divider for SC18IS600"))
+}
+
+func TestSC18IS600DriverWriteByteData(t *testing.T) {
+	d, c := initTestSC18IS600Driver()
+	conn, _ := d.GetConnection(0x40, d.GetDefaultBus())
+	gobottest.Assert(t, conn.WriteByteData(0x01, 0x02), nil)
+	gobottest.Assert(t, c.tx, [][]byte{{0x00, 0x02, 0x80, 0x01, 0x02}, sc18is600ReadStatus})
+}
+
+func TestSC18IS600DriverReadWordData(t *testing.T) {
+	d, c := initTestSC18IS600Driver()
+	c.buffer = []byte{0x34, 0x12}
+	conn, _ := d.GetConnection(0x40, d.GetDefaultBus())
+	val, err := conn.ReadWordData(0x07)
+	gobottest.Assert(t, err, nil)
+	gobottest.Assert(t, val, uint16(0x1234))
+	gobottest.Assert(t, c.tx, [][]byte{
+		{0x02, 0x01, 0x02, 0x80, 0x07, 0x81},
+		sc18is600ReadStatus,
+		{0x06, 0x00, 0x00},
+	})
+}
+
+func TestSC18IS600DriverRead(t *testing.T) {
+	d, c := initTestSC18IS600Driver()
+	c.buffer = []byte{0x01, 0x02, 0x03}
+	conn, _ := d.GetConnection(0x40, d.GetDefaultBus())
+	buf := make([]byte, 3)
+	n, err := conn.Read(buf)
+	gobottest.Assert(t, err, nil)
+	gobottest.Assert(t, n, 3)
+	gobottest.Assert(t, buf, []byte{0x01, 0x02, 0x03})
+	gobottest.Assert(t, c.tx[0], []byte{0x01, 0x03, 0x81})
+}
+
+func TestSC18IS600DriverBusyStatus(t *testing.T) {
+	d, c := initTestSC18IS600Driver()
+	c.status = []byte{sc18is600StatBusy, sc18is600StatBusy}
+	conn, _ := d.GetConnection(0x40, d.GetDefaultBus())
+	gobottest.Assert(t, conn.WriteByte(0x01), nil)
+	gobottest.Assert(t, len(c.tx), 4)
+
+	c.status = make([]byte, sc18is600StatusRetries)
+	for i := range c.status {
+		c.status[i] = sc18is600StatBusy
+	}
+	gobottest.Assert(t, conn.WriteByte(0x01), ErrSC18IS600Busy)
+}
+
+func TestSC18IS600DriverErrorStatus(t *testing.T) {
+	d, c := initTestSC18IS600Driver()
+	conn, _ := d.GetConnection(0x40, d.GetDefaultBus())
+
+	tests := map[byte]error{
+		sc18is600StatAddressNack:  ErrSC18IS600AddressNack,
+		sc18is600StatDataNack:     ErrSC18IS600DataNack,
+		sc18is600StatTimeout:      ErrSC18IS600Timeout,
+		sc18is600StatInvalidCount: ErrSC18IS600BufferSize,
+		0x42:                      errors.New("Unknown SC18IS600 I2C status 0x42"),
+	}
+	for status, expected := range tests {
+		c.status = []byte{status}
+		c.tx = nil
+		_, err := conn.ReadByteData(0x01)
+		gobottest.Assert(t, err, expected)
+		// the buffer is not read after a failed transfer
+		gobottest.Assert(t, len(c.tx), 2)
+	}
+}
+
+func TestSC18IS600DriverBufferSize(t *testing.T) {
+	d, _ := initTestSC18IS600Driver()
+	conn, _ := d.GetConnection(0x40, d.GetDefaultBus())
+	_, err := conn.Write(make([]byte, 97))
+	gobottest.Assert(t, err, ErrSC18IS600BufferSize)
+}
+
+func TestSC18IS600DriverTxError(t *testing.T) {
+	d, c := initTestSC18IS600Driver()
+	c.err = errors.New("tx error")
+	conn, _ := d.GetConnection(0x40, d.GetDefaultBus())
+	_, err := conn.ReadByteData(0x01)
+	gobottest.Assert(t, err, errors.New("tx error"))
+}
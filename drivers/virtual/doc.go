@@ -0,0 +1,12 @@
+/*
+Package virtual provides Gobot drivers for virtual devices, whose values are
+computed from the events of other devices.
+
+Installing:
+
+	go get -d -u gobot.io/x/gobot
+
+For further information refer to virtual README:
+https://github.com/hybridgroup/gobot/blob/master/drivers/virtual/README.md
+*/
+package virtual // import "gobot.io/x/gobot/drivers/virtual"
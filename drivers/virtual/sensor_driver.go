@@ -0,0 +1,210 @@
+package virtual
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	"gobot.io/x/gobot"
+)
+
+// Input feeds the data of the event Event published by Source into a
+// SensorDriver, as the sample called Name.
+type Input struct {
+	Name   string
+	Source gobot.Eventer
+	Event  string
+}
+
+// SensorFunc computes the value of a virtual sensor from the latest samples
+// of all its inputs.
+type SensorFunc func(samples gobot.Facts) (val interface{}, err error)
+
+// SensorDriver is a sensor computed from the samples of other devices. It
+// has no connection of its own, but otherwise behaves like a physical
+// sensor, so it can be added to a robot, read via the API and watched by a
+// RulesEngine. The radiant asymmetry between two thermometers reads:
+//
+//		asymmetry, err := virtual.NewSensorDriver(
+//			func(s gobot.Facts) (interface{}, error) {
+//				a, _ := s.Float("a")
+//				b, _ := s.Float("b")
+//				return a - b, nil
+//			},
+//			virtual.Input{Name: "a", Source: left, Event: "data"},
+//			virtual.Input{Name: "b", Source: right, Event: "data"},
+//		)
+//
+// Adds the following API Commands:
+//	"Read" - See SensorDriver.Read
+type SensorDriver struct {
+	name    string
+	compute SensorFunc
+	inputs  []Input
+	mutex   *sync.Mutex
+	samples gobot.Facts
+	value   interface{}
+	err     error
+	halt    chan bool
+	done    *sync.WaitGroup
+	gobot.Eventer
+	gobot.Commander
+}
+
+// NewSensorDriver returns a new SensorDriver computing its value with
+// compute from the given inputs. Inputs without a source, and inputs
+// sharing a name, are rejected.
+func NewSensorDriver(compute SensorFunc, inputs ...Input) (*SensorDriver, error) {
+	names := map[string]bool{}
+	for _, in := range inputs {
+		if in.Source == nil {
+			return nil, fmt.Errorf("virtual sensor input %s has no source", in.Name)
+		}
+		if names[in.Name] {
+			return nil, fmt.Errorf("duplicate virtual sensor input %s", in.Name)
+		}
+		names[in.Name] = true
+	}
+
+	v := &SensorDriver{
+		name:      gobot.DefaultName("VirtualSensor"),
+		compute:   compute,
+		inputs:    inputs,
+		mutex:     &sync.Mutex{},
+		samples:   gobot.Facts{},
+		err:       ErrNotReady,
+		done:      &sync.WaitGroup{},
+		Eventer:   gobot.NewEventer(),
+		Commander: gobot.NewCommander(),
+	}
+
+	v.AddEvent(Data)
+	v.AddEvent(Error)
+
+	v.AddCommand("Read", func(params map[string]interface{}) interface{} {
+		val, err := v.Read()
+		return map[string]interface{}{"val": val, "err": err}
+	})
+
+	return v, nil
+}
+
+// Name returns the SensorDrivers name
+func (v *SensorDriver) Name() string { return v.name }
+
+// SetName sets the SensorDrivers name
+func (v *SensorDriver) SetName(n string) { v.name = n }
+
+// Connection returns nil, a virtual sensor has no connection
+func (v *SensorDriver) Connection() gobot.Connection { return nil }
+
+// Start starts listening to the inputs. The value is computed whenever an
+// input publishes a new sample, once every input has published at least one.
+// Starting a sensor which is already started fails.
+// Emits the Events:
+//	Data interface{} - the newly computed value
+//	Error error - computing the value failed
+func (v *SensorDriver) Start() (err error) {
+	v.mutex.Lock()
+	defer v.mutex.Unlock()
+
+	if v.halt != nil {
+		return errors.New("Virtual sensor is already started")
+	}
+	halt := make(chan bool)
+	v.halt = halt
+
+	for _, in := range v.inputs {
+		out := in.Source.Subscribe()
+		v.done.Add(1)
+		go func(in Input) {
+			defer v.done.Done()
+			for {
+				select {
+				case evt := <-out:
+					if evt.Name == in.Event {
+						v.update(in.Name, evt.Data)
+					}
+				case <-halt:
+					gobot.UnsubscribeAndDrain(in.Source, out)
+					return
+				}
+			}
+		}(in)
+	}
+	return
+}
+
+// Halt stops listening to the inputs. It returns once the sensor has
+// unsubscribed from all of them.
+func (v *SensorDriver) Halt() (err error) {
+	v.mutex.Lock()
+	halt := v.halt
+	v.halt = nil
+	v.mutex.Unlock()
+
+	if halt != nil {
+		close(halt)
+		v.done.Wait()
+	}
+	return
+}
+
+// Health returns gobot.HealthDisconnected until every input has published a
+// sample, gobot.HealthFault with the error while computing the value fails,
+// and gobot.HealthOK otherwise.
+func (v *SensorDriver) Health() (gobot.HealthState, error) {
+	v.mutex.Lock()
+	defer v.mutex.Unlock()
+
+	switch {
+	case v.err == ErrNotReady:
+		return gobot.HealthDisconnected, v.err
+	case v.err != nil:
+		return gobot.HealthFault, v.err
+	}
+	return gobot.HealthOK, nil
+}
+
+// Read returns the last computed value. ErrNotReady is returned until every
+// input has published a sample.
+func (v *SensorDriver) Read() (val interface{}, err error) {
+	v.mutex.Lock()
+	defer v.mutex.Unlock()
+
+	return v.value, v.err
+}
+
+func (v *SensorDriver) update(name string, data interface{}) {
+	v.mutex.Lock()
+	v.samples[name] = data
+	if len(v.samples) < len(v.inputs) {
+		v.mutex.Unlock()
+		return
+	}
+
+	samples := gobot.Facts{}
+	for n, s := range v.samples {
+		samples[n] = s
+	}
+	val, err := v.compute(samples)
+	if err == nil {
+		v.value = val
+	}
+	v.err = err
+	v.mutex.Unlock()
+
+	if err != nil {
+		v.Publish(v.Event(Error), err)
+		return
+	}
+	v.Publish(v.Event(Data), val)
+}
+
+// ExpressionSensorFunc returns a SensorFunc evaluating e against the
+// samples, which are available by input name.
+func ExpressionSensorFunc(e *gobot.Expression) SensorFunc {
+	return func(samples gobot.Facts) (interface{}, error) {
+		return e.Eval(samples)
+	}
+}
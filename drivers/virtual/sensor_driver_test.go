@@ -0,0 +1,191 @@
+package virtual
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"gobot.io/x/gobot"
+	"gobot.io/x/gobot/gobottest"
+)
+
+var _ gobot.Driver = (*SensorDriver)(nil)
+var _ gobot.HealthChecker = (*SensorDriver)(nil)
+
+func initTestSensorDriver() (*SensorDriver, gobot.Eventer, gobot.Eventer) {
+	a := gobot.NewEventer()
+	a.AddEvent("data")
+	b := gobot.NewEventer()
+	b.AddEvent("data")
+
+	v, _ := NewSensorDriver(
+		func(s gobot.Facts) (interface{}, error) {
+			x, _ := s.Float("a")
+			y, _ := s.Float("b")
+			if y < 0 {
+				return nil, errors.New("negative")
+			}
+			return x - y, nil
+		},
+		Input{Name: "a", Source: a, Event: "data"},
+		Input{Name: "b", Source: b, Event: "data"},
+	)
+	return v, a, b
+}
+
+func TestNewSensorDriver(t *testing.T) {
+	v, _, _ := initTestSensorDriver()
+	gobottest.Assert(t, v.Name()[:13], "VirtualSensor")
+	gobottest.Assert(t, v.Connection(), nil)
+	gobottest.Refute(t, v.Command("Read"), nil)
+
+	v.SetName("asymmetry")
+	gobottest.Assert(t, v.Name(), "asymmetry")
+
+	ret := v.Command("Read")(nil).(map[string]interface{})
+	gobottest.Assert(t, ret["val"], nil)
+	gobottest.Assert(t, ret["err"], ErrNotReady)
+}
+
+func TestSensorDriverData(t *testing.T) {
+	v, a, b := initTestSensorDriver()
+	gobottest.Assert(t, v.Start(), nil)
+	defer v.Halt()
+
+	sem := make(chan interface{}, 1)
+	v.Once(v.Event(Data), func(data interface{}) {
+		sem <- data
+	})
+
+	a.Publish("data", 25.5)
+	time.Sleep(10 * time.Millisecond)
+	_, err := v.Read()
+	gobottest.Assert(t, err, ErrNotReady)
+
+	b.Publish("data", 21)
+	select {
+	case data := <-sem:
+		gobottest.Assert(t, data, 4.5)
+	case <-time.After(time.Second):
+		t.Errorf("Data was not published")
+	}
+
+	val, err := v.Read()
+	gobottest.Assert(t, val, 4.5)
+	gobottest.Assert(t, err, nil)
+}
+
+func TestSensorDriverError(t *testing.T) {
+	v, a, b := initTestSensorDriver()
+	gobottest.Assert(t, v.Start(), nil)
+	defer v.Halt()
+
+	sem := make(chan interface{}, 1)
+	v.Once(v.Event(Error), func(data interface{}) {
+		sem <- data
+	})
+
+	a.Publish("data", 1)
+	b.Publish("data", -1)
+	select {
+	case err := <-sem:
+		gobottest.Assert(t, err, errors.New("negative"))
+	case <-time.After(time.Second):
+		t.Errorf("Error was not published")
+	}
+}
+
+func TestSensorDriverHealth(t *testing.T) {
+	v, _, _ := initTestSensorDriver()
+	state, err := v.Health()
+	gobottest.Assert(t, state, gobot.HealthDisconnected)
+	gobottest.Assert(t, err, ErrNotReady)
+
+	v.update("a", 1.0)
+	v.update("b", -1.0)
+	state, err = v.Health()
+	gobottest.Assert(t, state, gobot.HealthFault)
+	gobottest.Assert(t, err, errors.New("negative"))
+
+	v.update("b", 1.0)
+	state, err = v.Health()
+	gobottest.Assert(t, state, gobot.HealthOK)
+	gobottest.Assert(t, err, nil)
+}
+
+func TestNewSensorDriverInputErrors(t *testing.T) {
+	a := gobot.NewEventer()
+	_, err := NewSensorDriver(nil, Input{Name: "a", Source: a}, Input{Name: "a", Source: a})
+	gobottest.Assert(t, err, errors.New("duplicate virtual sensor input a"))
+
+	_, err = NewSensorDriver(nil, Input{Name: "a"})
+	gobottest.Assert(t, err, errors.New("virtual sensor input a has no source"))
+}
+
+func TestSensorDriverStartTwice(t *testing.T) {
+	v, _, _ := initTestSensorDriver()
+	gobottest.Assert(t, v.Start(), nil)
+	gobottest.Assert(t, v.Start(), errors.New("Virtual sensor is already started"))
+	gobottest.Assert(t, v.Halt(), nil)
+
+	// it can be started again once halted
+	gobottest.Assert(t, v.Start(), nil)
+	gobottest.Assert(t, v.Halt(), nil)
+	gobottest.Assert(t, v.Halt(), nil)
+}
+
+func TestSensorDriverHaltWhileFlooded(t *testing.T) {
+	v, a, b := initTestSensorDriver()
+	gobottest.Assert(t, v.Start(), nil)
+
+	// keep the input busy, so its events may fill the subscription
+	stop := make(chan bool)
+	go func() {
+		for {
+			select {
+			case <-stop:
+				return
+			default:
+				a.Publish("data", 1)
+			}
+		}
+	}()
+	defer close(stop)
+	b.Publish("data", 1)
+
+	done := make(chan bool)
+	go func() {
+		v.Halt()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Errorf("Halt did not return")
+	}
+}
+
+func TestSensorDriverHalt(t *testing.T) {
+	v, a, b := initTestSensorDriver()
+	gobottest.Assert(t, v.Start(), nil)
+	gobottest.Assert(t, v.Halt(), nil)
+
+	a.Publish("data", 1)
+	b.Publish("data", 1)
+	time.Sleep(10 * time.Millisecond)
+	_, err := v.Read()
+	gobottest.Assert(t, err, ErrNotReady)
+}
+
+func TestExpressionSensorFunc(t *testing.T) {
+	e, err := gobot.CompileExpression("(a - b) / 2")
+	gobottest.Assert(t, err, nil)
+
+	f := ExpressionSensorFunc(e)
+	val, err := f(gobot.Facts{"a": 25, "b": 21})
+	gobottest.Assert(t, val, 2.0)
+	gobottest.Assert(t, err, nil)
+
+	_, err = f(gobot.Facts{"a": 25})
+	gobottest.Assert(t, err.Error(), "unknown fact b")
+}
@@ -0,0 +1,16 @@
+package virtual
+
+import "errors"
+
+var (
+	// ErrNotReady is the error returned until every input of a virtual
+	// device has published a sample
+	ErrNotReady = errors.New("Device is not ready")
+)
+
+const (
+	// Error event
+	Error = "error"
+	// Data event
+	Data = "data"
+)
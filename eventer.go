@@ -120,6 +120,25 @@ func (e *eventer) Unsubscribe(events eventChannel) {
 	delete(e.outs, events)
 }
 
+// UnsubscribeAndDrain unsubscribes events from source, discarding the events
+// still sent to it meanwhile. Unlike Unsubscribe alone, it cannot deadlock
+// when the subscriber has stopped reading from a full events channel.
+func UnsubscribeAndDrain(source Eventer, events eventChannel) {
+	done := make(chan struct{})
+	go func() {
+		source.Unsubscribe(events)
+		close(done)
+	}()
+
+	for {
+		select {
+		case <-events:
+		case <-done:
+			return
+		}
+	}
+}
+
 // On executes the event handler f when e is Published to.
 func (e *eventer) On(n string, f func(s interface{})) (err error) {
 	out := e.Subscribe()
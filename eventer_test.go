@@ -76,3 +76,25 @@ func TestEventerOnce(t *testing.T) {
 	case <-time.After(10 * time.Millisecond):
 	}
 }
+
+func TestUnsubscribeAndDrain(t *testing.T) {
+	e := NewEventer()
+	e.AddEvent("test")
+	out := e.Subscribe()
+
+	// fill the channel, so the dispatching goroutine blocks on it
+	for i := 0; i < 2*eventChanBufferSize; i++ {
+		e.Publish("test", i)
+	}
+
+	done := make(chan bool)
+	go func() {
+		UnsubscribeAndDrain(e, out)
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatalf("UnsubscribeAndDrain did not return")
+	}
+}
@@ -0,0 +1,513 @@
+package gobot
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Expression is a compiled expression over facts, like
+//
+//		(obj - amb) > 4 && presence > 100
+//
+// Expressions know numbers, the constants true and false, and the names of
+// facts. Numbers support the arithmetic operators + - * / and the
+// comparisons < <= > >= == !=, booleans the logical operators && || !.
+// Parentheses group as usual. Expressions are parsed and type checked once
+// by CompileExpression, so configuration errors show up when the expression
+// is loaded rather than when it is evaluated. The type of a fact follows
+// from the operators it is used with, a fact used both as a number and as a
+// bool is an error.
+type Expression struct {
+	source string
+	root   exprNode
+}
+
+// CompileExpression parses source into an Expression.
+func CompileExpression(source string) (*Expression, error) {
+	p := &exprParser{source: source}
+	if err := p.tokenize(); err != nil {
+		return nil, err
+	}
+
+	root, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.tokens) {
+		return nil, p.errorf("unexpected %q", p.tokens[p.pos].text)
+	}
+	if _, err := root.check(exprTypes{}, exprAny); err != nil {
+		return nil, fmt.Errorf("expression %q: %v", source, err)
+	}
+	return &Expression{source: source, root: root}, nil
+}
+
+// String returns the source of the expression.
+func (e *Expression) String() string { return e.source }
+
+// Names returns the names of the facts the expression refers to.
+func (e *Expression) Names() []string {
+	names := []string{}
+	seen := map[string]bool{}
+	e.root.names(func(name string) {
+		if !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	})
+	return names
+}
+
+// Eval evaluates the expression against facts, the result is either a
+// float64 or a bool. Missing facts and mismatching types are errors.
+func (e *Expression) Eval(facts Facts) (val interface{}, err error) {
+	return e.root.eval(facts)
+}
+
+// Bool evaluates an expression resulting in a bool.
+func (e *Expression) Bool(facts Facts) (bool, error) {
+	val, err := e.Eval(facts)
+	if err != nil {
+		return false, err
+	}
+	b, ok := val.(bool)
+	if !ok {
+		return false, fmt.Errorf("expression %q is not a condition", e.source)
+	}
+	return b, nil
+}
+
+// Float evaluates an expression resulting in a number.
+func (e *Expression) Float(facts Facts) (float64, error) {
+	val, err := e.Eval(facts)
+	if err != nil {
+		return 0, err
+	}
+	f, ok := val.(float64)
+	if !ok {
+		return 0, fmt.Errorf("expression %q is not a number", e.source)
+	}
+	return f, nil
+}
+
+// Condition returns the expression as condition of a Rule of engine.
+// Evaluation errors are treated as false. Except for facts not yet known,
+// they are published as RuleError of engine.
+func (e *Expression) Condition(engine *RulesEngine) func(f Facts) bool {
+	return func(f Facts) bool {
+		b, err := e.Bool(f)
+		if err != nil {
+			if _, unknown := err.(exprUnknownFact); !unknown {
+				engine.Publish(RuleError, fmt.Errorf("expression %q: %v", e.source, err))
+			}
+			return false
+		}
+		return b
+	}
+}
+
+// exprUnknownFact is the evaluation error of a fact missing from the facts.
+type exprUnknownFact string
+
+func (name exprUnknownFact) Error() string { return "unknown fact " + string(name) }
+
+// exprType is the type of a node, exprAny when it can not be told before
+// evaluation.
+type exprType int
+
+const (
+	exprAny exprType = iota
+	exprBool
+	exprNumber
+)
+
+func (t exprType) String() string {
+	switch t {
+	case exprBool:
+		return "a bool"
+	case exprNumber:
+		return "a number"
+	}
+	return "any value"
+}
+
+// exprTypes holds the types of the facts inferred so far.
+type exprTypes map[string]exprType
+
+type exprNode interface {
+	eval(facts Facts) (interface{}, error)
+	names(f func(name string))
+	// check returns the type of the node. want is the type the operator
+	// applied to the node expects, facts take it as their type.
+	check(types exprTypes, want exprType) (exprType, error)
+}
+
+// checkOperand checks that node has the type op expects.
+func checkOperand(op string, node exprNode, types exprTypes, want exprType) error {
+	t, err := node.check(types, want)
+	if err != nil {
+		return err
+	}
+	if t != exprAny && t != want {
+		return fmt.Errorf("operator %s can not be applied to %s", op, t)
+	}
+	return nil
+}
+
+type exprLiteral struct{ val interface{} }
+
+func (n exprLiteral) eval(facts Facts) (interface{}, error) { return n.val, nil }
+func (n exprLiteral) names(f func(name string))             {}
+
+func (n exprLiteral) check(types exprTypes, want exprType) (exprType, error) {
+	if _, ok := n.val.(bool); ok {
+		return exprBool, nil
+	}
+	return exprNumber, nil
+}
+
+type exprFact struct{ name string }
+
+func (n exprFact) eval(facts Facts) (interface{}, error) {
+	val, ok := facts[n.name]
+	if !ok {
+		return nil, exprUnknownFact(n.name)
+	}
+	if b, ok := val.(bool); ok {
+		return b, nil
+	}
+	if f, ok := facts.Float(n.name); ok {
+		return f, nil
+	}
+	return nil, fmt.Errorf("fact %s is neither a number nor a bool", n.name)
+}
+
+func (n exprFact) names(f func(name string)) { f(n.name) }
+
+func (n exprFact) check(types exprTypes, want exprType) (exprType, error) {
+	t := types[n.name]
+	if want == exprAny {
+		return t, nil
+	}
+	if t != exprAny && t != want {
+		return t, fmt.Errorf("fact %s is used as %s and as %s", n.name, t, want)
+	}
+	types[n.name] = want
+	return want, nil
+}
+
+type exprUnary struct {
+	op      string
+	operand exprNode
+}
+
+func (n exprUnary) eval(facts Facts) (interface{}, error) {
+	val, err := n.operand.eval(facts)
+	if err != nil {
+		return nil, err
+	}
+	switch v := val.(type) {
+	case bool:
+		if n.op == "!" {
+			return !v, nil
+		}
+	case float64:
+		if n.op == "-" {
+			return -v, nil
+		}
+	}
+	return nil, fmt.Errorf("operator %s can not be applied to %v", n.op, val)
+}
+
+func (n exprUnary) names(f func(name string)) { n.operand.names(f) }
+
+func (n exprUnary) check(types exprTypes, want exprType) (exprType, error) {
+	t := exprNumber
+	if n.op == "!" {
+		t = exprBool
+	}
+	return t, checkOperand(n.op, n.operand, types, t)
+}
+
+type exprBinary struct {
+	op          string
+	left, right exprNode
+}
+
+func (n exprBinary) eval(facts Facts) (interface{}, error) {
+	left, err := n.left.eval(facts)
+	if err != nil {
+		return nil, err
+	}
+
+	// && and || only evaluate the right side when needed
+	if l, ok := left.(bool); ok && (n.op == "&&" || n.op == "||") {
+		if l == (n.op == "||") {
+			return l, nil
+		}
+		right, err := n.right.eval(facts)
+		if err != nil {
+			return nil, err
+		}
+		if r, ok := right.(bool); ok {
+			return r, nil
+		}
+		return nil, fmt.Errorf("operator %s can not be applied to %v", n.op, right)
+	}
+
+	right, err := n.right.eval(facts)
+	if err != nil {
+		return nil, err
+	}
+
+	if l, ok := left.(bool); ok {
+		if r, ok := right.(bool); ok {
+			switch n.op {
+			case "==":
+				return l == r, nil
+			case "!=":
+				return l != r, nil
+			}
+		}
+		return nil, fmt.Errorf("operator %s can not be applied to %v and %v", n.op, left, right)
+	}
+
+	l, lok := left.(float64)
+	r, rok := right.(float64)
+	if !lok || !rok {
+		return nil, fmt.Errorf("operator %s can not be applied to %v and %v", n.op, left, right)
+	}
+	switch n.op {
+	case "+":
+		return l + r, nil
+	case "-":
+		return l - r, nil
+	case "*":
+		return l * r, nil
+	case "/":
+		if r == 0 {
+			return nil, fmt.Errorf("division by zero")
+		}
+		return l / r, nil
+	case "<":
+		return l < r, nil
+	case "<=":
+		return l <= r, nil
+	case ">":
+		return l > r, nil
+	case ">=":
+		return l >= r, nil
+	case "==":
+		return l == r, nil
+	case "!=":
+		return l != r, nil
+	}
+	return nil, fmt.Errorf("operator %s can not be applied to %v and %v", n.op, left, right)
+}
+
+func (n exprBinary) names(f func(name string)) {
+	n.left.names(f)
+	n.right.names(f)
+}
+
+func (n exprBinary) check(types exprTypes, want exprType) (exprType, error) {
+	switch n.op {
+	case "&&", "||":
+		if err := checkOperand(n.op, n.left, types, exprBool); err != nil {
+			return exprBool, err
+		}
+		return exprBool, checkOperand(n.op, n.right, types, exprBool)
+	case "==", "!=":
+		// both sides have the same type, which ever side tells it
+		left, err := n.left.check(types, exprAny)
+		if err != nil {
+			return exprBool, err
+		}
+		right, err := n.right.check(types, left)
+		if err != nil {
+			return exprBool, err
+		}
+		if left == exprAny && right != exprAny {
+			left, err = n.left.check(types, right)
+			if err != nil {
+				return exprBool, err
+			}
+		}
+		if left != exprAny && right != exprAny && left != right {
+			return exprBool, fmt.Errorf("operator %s can not be applied to %s and %s", n.op, left, right)
+		}
+		return exprBool, nil
+	}
+
+	t := exprNumber
+	switch n.op {
+	case "<", "<=", ">", ">=":
+		t = exprBool
+	}
+	if err := checkOperand(n.op, n.left, types, exprNumber); err != nil {
+		return t, err
+	}
+	return t, checkOperand(n.op, n.right, types, exprNumber)
+}
+
+type exprToken struct {
+	text string
+	pos  int
+}
+
+type exprParser struct {
+	source string
+	tokens []exprToken
+	pos    int
+}
+
+var exprOperators = []string{"&&", "||", "<=", ">=", "==", "!=", "<", ">", "+", "-", "*", "/", "!", "(", ")"}
+
+func (p *exprParser) tokenize() error {
+	for i := 0; i < len(p.source); {
+		c := rune(p.source[i])
+		switch {
+		case unicode.IsSpace(c):
+			i++
+		case unicode.IsDigit(c) || c == '.':
+			start := i
+			for i < len(p.source) && (unicode.IsDigit(rune(p.source[i])) || p.source[i] == '.') {
+				i++
+			}
+			p.tokens = append(p.tokens, exprToken{text: p.source[start:i], pos: start})
+		case unicode.IsLetter(c) || c == '_':
+			start := i
+			for i < len(p.source) && isExprNameChar(rune(p.source[i])) {
+				i++
+			}
+			p.tokens = append(p.tokens, exprToken{text: p.source[start:i], pos: start})
+		default:
+			op := ""
+			for _, o := range exprOperators {
+				if strings.HasPrefix(p.source[i:], o) {
+					op = o
+					break
+				}
+			}
+			if op == "" {
+				return fmt.Errorf("expression %q: unexpected %q at %d", p.source, c, i)
+			}
+			p.tokens = append(p.tokens, exprToken{text: op, pos: i})
+			i += len(op)
+		}
+	}
+	return nil
+}
+
+func isExprNameChar(c rune) bool {
+	return unicode.IsLetter(c) || unicode.IsDigit(c) || c == '_' || c == '.'
+}
+
+func (p *exprParser) errorf(format string, args ...interface{}) error {
+	pos := len(p.source)
+	if p.pos < len(p.tokens) {
+		pos = p.tokens[p.pos].pos
+	}
+	return fmt.Errorf("expression %q: %s at %d", p.source, fmt.Sprintf(format, args...), pos)
+}
+
+// accept consumes the next token if it is one of ops.
+func (p *exprParser) accept(ops ...string) (string, bool) {
+	if p.pos >= len(p.tokens) {
+		return "", false
+	}
+	for _, op := range ops {
+		if p.tokens[p.pos].text == op {
+			p.pos++
+			return op, true
+		}
+	}
+	return "", false
+}
+
+func (p *exprParser) parseBinary(next func() (exprNode, error), ops ...string) (exprNode, error) {
+	left, err := next()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		op, ok := p.accept(ops...)
+		if !ok {
+			return left, nil
+		}
+		right, err := next()
+		if err != nil {
+			return nil, err
+		}
+		left = exprBinary{op: op, left: left, right: right}
+	}
+}
+
+func (p *exprParser) parseOr() (exprNode, error) {
+	return p.parseBinary(p.parseAnd, "||")
+}
+
+func (p *exprParser) parseAnd() (exprNode, error) {
+	return p.parseBinary(p.parseComparison, "&&")
+}
+
+func (p *exprParser) parseComparison() (exprNode, error) {
+	return p.parseBinary(p.parseSum, "<=", ">=", "==", "!=", "<", ">")
+}
+
+func (p *exprParser) parseSum() (exprNode, error) {
+	return p.parseBinary(p.parseProduct, "+", "-")
+}
+
+func (p *exprParser) parseProduct() (exprNode, error) {
+	return p.parseBinary(p.parseUnary, "*", "/")
+}
+
+func (p *exprParser) parseUnary() (exprNode, error) {
+	if op, ok := p.accept("!", "-"); ok {
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return exprUnary{op: op, operand: operand}, nil
+	}
+	return p.parsePrimary()
+}
+
+func (p *exprParser) parsePrimary() (exprNode, error) {
+	if p.pos >= len(p.tokens) {
+		return nil, p.errorf("unexpected end")
+	}
+
+	if _, ok := p.accept("("); ok {
+		node, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := p.accept(")"); !ok {
+			return nil, p.errorf("missing )")
+		}
+		return node, nil
+	}
+
+	t := p.tokens[p.pos]
+	c := rune(t.text[0])
+	switch {
+	case unicode.IsDigit(c) || c == '.':
+		f, err := strconv.ParseFloat(t.text, 64)
+		if err != nil {
+			return nil, p.errorf("invalid number %q", t.text)
+		}
+		p.pos++
+		return exprLiteral{val: f}, nil
+	case t.text == "true" || t.text == "false":
+		p.pos++
+		return exprLiteral{val: t.text == "true"}, nil
+	case unicode.IsLetter(c) || c == '_':
+		p.pos++
+		return exprFact{name: t.text}, nil
+	}
+	return nil, p.errorf("unexpected %q", t.text)
+}
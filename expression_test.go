@@ -0,0 +1,166 @@
+package gobot
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"gobot.io/x/gobot/gobottest"
+)
+
+func TestCompileExpressionErrors(t *testing.T) {
+	_, err := CompileExpression("obj >")
+	gobottest.Assert(t, err, errors.New(`expression "obj >": unexpected end at 5`))
+
+	_, err = CompileExpression("(obj - amb > 4")
+	gobottest.Assert(t, err, errors.New(`expression "(obj - amb > 4": missing ) at 14`))
+
+	_, err = CompileExpression("obj # 4")
+	gobottest.Assert(t, err, errors.New(`expression "obj # 4": unexpected '#' at 4`))
+
+	_, err = CompileExpression("obj 4")
+	gobottest.Assert(t, err, errors.New(`expression "obj 4": unexpected "4" at 4`))
+
+	_, err = CompileExpression("1.2.3")
+	gobottest.Assert(t, err, errors.New(`expression "1.2.3": invalid number "1.2.3" at 0`))
+}
+
+func TestCompileExpressionTypeErrors(t *testing.T) {
+	tests := map[string]error{
+		"temp > 30 && 5":     errors.New(`expression "temp > 30 && 5": operator && can not be applied to a number`),
+		"!5":                 errors.New(`expression "!5": operator ! can not be applied to a number`),
+		"-true":              errors.New(`expression "-true": operator - can not be applied to a bool`),
+		"(temp > 30) + 1":    errors.New(`expression "(temp > 30) + 1": operator + can not be applied to a bool`),
+		"true == 1":          errors.New(`expression "true == 1": operator == can not be applied to a bool and a number`),
+		"temp > 30 && !temp": errors.New(`expression "temp > 30 && !temp": fact temp is used as a number and as a bool`),
+		"door == true && door > 1": errors.New(
+			`expression "door == true && door > 1": fact door is used as a bool and as a number`),
+		"door && door == 1": errors.New(`expression "door && door == 1": operator == can not be applied to a bool and a number`),
+	}
+	for source, expected := range tests {
+		_, err := CompileExpression(source)
+		gobottest.Assert(t, err, expected)
+	}
+
+	for _, source := range []string{"!door", "a == b", "a == b && a > 1", "1 == a && !(b != true)"} {
+		_, err := CompileExpression(source)
+		gobottest.Assert(t, err, nil)
+	}
+}
+
+func TestExpressionEval(t *testing.T) {
+	facts := Facts{"obj": 30.5, "amb": 25, "presence": uint16(120), "door": true}
+
+	tests := map[string]interface{}{
+		"1 + 2 * 3":                          7.0,
+		"(1 + 2) * 3":                        9.0,
+		"10 - 4 - 3":                         3.0,
+		"-obj + 1":                           -29.5,
+		"obj - amb":                          5.5,
+		"(obj - amb) > 4 && presence > 100":  true,
+		"obj - amb > 6 || !door":             false,
+		"door == true":                       true,
+		"presence >= 120 && presence <= 120": true,
+		"amb != 25":                          false,
+		"false || (obj / 2 == 15.25)":        true,
+		"sensor_a.obj_1 < 1 || true":         true,
+	}
+	facts["sensor_a.obj_1"] = 0
+	for source, expected := range tests {
+		e, err := CompileExpression(source)
+		gobottest.Assert(t, err, nil)
+		val, err := e.Eval(facts)
+		gobottest.Assert(t, err, nil)
+		if val != expected {
+			t.Errorf("%s: expected %v, got %v", source, expected, val)
+		}
+	}
+}
+
+func TestExpressionEvalErrors(t *testing.T) {
+	facts := Facts{"obj": 30.5, "door": true, "name": "hall"}
+
+	tests := map[string]error{
+		"missing > 1": exprUnknownFact("missing"),
+		"name > 1":    errors.New("fact name is neither a number nor a bool"),
+		"door + 1":    errors.New("operator + can not be applied to true and 1"),
+		"obj && door": errors.New("operator && can not be applied to 30.5 and true"),
+		"door && obj": errors.New("operator && can not be applied to 30.5"),
+		"!obj":        errors.New("operator ! can not be applied to 30.5"),
+		"obj / 0":     errors.New("division by zero"),
+	}
+	for source, expected := range tests {
+		e, err := CompileExpression(source)
+		gobottest.Assert(t, err, nil)
+		_, err = e.Eval(facts)
+		gobottest.Assert(t, err, expected)
+	}
+
+	// && and || skip the right side when the left side decides
+	e, _ := CompileExpression("!door && missing > 1")
+	val, err := e.Eval(facts)
+	gobottest.Assert(t, val, false)
+	gobottest.Assert(t, err, nil)
+}
+
+func TestExpressionBoolFloat(t *testing.T) {
+	e, _ := CompileExpression("obj - amb")
+	gobottest.Assert(t, e.String(), "obj - amb")
+	gobottest.Assert(t, e.Names(), []string{"obj", "amb"})
+
+	f, err := e.Float(Facts{"obj": 3, "amb": 1})
+	gobottest.Assert(t, f, 2.0)
+	gobottest.Assert(t, err, nil)
+	_, err = e.Bool(Facts{"obj": 3, "amb": 1})
+	gobottest.Assert(t, err, errors.New(`expression "obj - amb" is not a condition`))
+
+	e, _ = CompileExpression("obj > amb")
+	b, err := e.Bool(Facts{"obj": 3, "amb": 1})
+	gobottest.Assert(t, b, true)
+	gobottest.Assert(t, err, nil)
+	_, err = e.Float(Facts{"obj": 3, "amb": 1})
+	gobottest.Assert(t, err, errors.New(`expression "obj > amb" is not a number`))
+}
+
+func TestExpressionCondition(t *testing.T) {
+	e, _ := CompileExpression("temp > 30 && presence")
+	engine := NewRulesEngine()
+	sem := make(chan interface{}, 1)
+	engine.Once(RuleTriggered, func(data interface{}) {
+		sem <- data
+	})
+	engine.AddRule(Rule{Name: "hot", Condition: e.Condition(engine)})
+
+	engine.Set("temp", 31)
+	gobottest.Assert(t, len(sem), 0)
+	engine.Set("presence", true)
+
+	select {
+	case name := <-sem:
+		gobottest.Assert(t, name, "hot")
+	case <-time.After(time.Second):
+		t.Errorf("RuleTriggered was not published")
+	}
+}
+
+func TestExpressionConditionError(t *testing.T) {
+	e, _ := CompileExpression("temp / rate > 1")
+	engine := NewRulesEngine()
+	sem := make(chan interface{}, 1)
+	engine.Once(RuleError, func(data interface{}) {
+		sem <- data
+	})
+	engine.AddRule(Rule{Name: "fast", Condition: e.Condition(engine)})
+
+	// facts not yet known are no error
+	engine.Set("temp", 31)
+	gobottest.Assert(t, len(sem), 0)
+	engine.Set("rate", 0)
+
+	select {
+	case err := <-sem:
+		gobottest.Assert(t, err, errors.New(`expression "temp / rate > 1": division by zero`))
+	case <-time.After(time.Second):
+		t.Errorf("RuleError was not published")
+	}
+}
@@ -0,0 +1,176 @@
+package gobot
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+)
+
+// processStarted is the time the process started, close enough for uptimes.
+var processStarted = time.Now()
+
+// HealthState is the state of a device as reported by a HealthChecker.
+type HealthState int
+
+const (
+	// HealthOK is the state of a device working as expected
+	HealthOK HealthState = iota
+	// HealthWarning is the state of a working device needing attention,
+	// like a sensor due for calibration
+	HealthWarning
+	// HealthFault is the state of a device failing to work
+	HealthFault
+	// HealthDisconnected is the state of a device which is not started or
+	// can not be reached
+	HealthDisconnected
+)
+
+// String returns the name of the state, as used in JSONDeviceHealth.
+func (s HealthState) String() string {
+	switch s {
+	case HealthOK:
+		return "ok"
+	case HealthWarning:
+		return "warning"
+	case HealthFault:
+		return "fault"
+	case HealthDisconnected:
+		return "disconnected"
+	}
+	return fmt.Sprintf("HealthState(%d)", int(s))
+}
+
+// HealthChecker is implemented by devices that can report whether they are
+// in a working state. Health returns the state of the device, and the
+// error causing it for any state but HealthOK.
+type HealthChecker interface {
+	Health() (HealthState, error)
+}
+
+// JSONLiveness is a JSON representation of a live process. Uptime is the
+// time since the process started.
+type JSONLiveness struct {
+	Uptime float64 `json:"uptime_seconds"`
+}
+
+// JSONHealth is a JSON representation of the health of a Gobot Master.
+// Uptime is the time since the process started.
+type JSONHealth struct {
+	Healthy  bool               `json:"healthy"`
+	Uptime   float64            `json:"uptime_seconds"`
+	Robots   []*JSONRobotHealth `json:"robots"`
+	Counters map[string]int     `json:"counters"`
+}
+
+// JSONRobotHealth is a JSON representation of the health of a Robot.
+type JSONRobotHealth struct {
+	Name    string              `json:"name"`
+	Running bool                `json:"running"`
+	Devices []*JSONDeviceHealth `json:"devices"`
+}
+
+// JSONDeviceHealth is a JSON representation of the health of a Device.
+// Devices in the HealthOK and HealthWarning states are healthy.
+type JSONDeviceHealth struct {
+	Name    string `json:"name"`
+	Healthy bool   `json:"healthy"`
+	State   string `json:"state"`
+	Error   string `json:"error,omitempty"`
+}
+
+// NewJSONHealth returns a JSONHealth given a Gobot Master. The Master is
+// healthy when all of its robots are running and none of their devices
+// implementing HealthChecker reports a fault or is disconnected. Devices
+// not implementing HealthChecker are reported as HealthOK.
+func NewJSONHealth(master *Master) *JSONHealth {
+	h := &JSONHealth{
+		Healthy:  true,
+		Uptime:   time.Since(processStarted).Seconds(),
+		Robots:   []*JSONRobotHealth{},
+		Counters: map[string]int{"robots": 0, "connections": 0, "devices": 0, "unhealthy_devices": 0},
+	}
+
+	master.Robots().Each(func(r *Robot) {
+		rh := &JSONRobotHealth{
+			Name:    r.Name,
+			Running: r.Running(),
+			Devices: []*JSONDeviceHealth{},
+		}
+		if !rh.Running {
+			h.Healthy = false
+		}
+
+		r.Devices().Each(func(d Device) {
+			dh := &JSONDeviceHealth{Name: d.Name(), Healthy: true, State: HealthOK.String()}
+			if checker, ok := d.(HealthChecker); ok {
+				state, err := checker.Health()
+				dh.State = state.String()
+				if err != nil {
+					dh.Error = err.Error()
+				}
+				if state != HealthOK && state != HealthWarning {
+					dh.Healthy = false
+					h.Healthy = false
+					h.Counters["unhealthy_devices"]++
+				}
+			}
+			rh.Devices = append(rh.Devices, dh)
+		})
+
+		h.Counters["robots"]++
+		h.Counters["connections"] += r.Connections().Len()
+		h.Counters["devices"] += r.Devices().Len()
+		h.Robots = append(h.Robots, rh)
+	})
+	return h
+}
+
+// NewHealthHandler returns an http.Handler serving the health of master on
+// a single endpoint. By default it serves the JSONHealth of master as
+// readiness probe, responding with 200 OK when the Master is healthy and
+// with 503 Service Unavailable otherwise.
+//
+// With the query parameter probe=live it serves the JSONLiveness of the
+// process as liveness probe instead. It always responds with 200 OK while the
+// process serves requests, regardless of the health of robots and devices,
+// so an orchestrator does not restart the process for a broken sensor.
+//
+// The handler does not depend on the api package and can be mounted on any
+// existing HTTP server:
+//
+//		http.Handle("/health", gobot.NewHealthHandler(master))
+//
+func NewHealthHandler(master *Master) http.Handler {
+	return http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		res.Header().Set("Content-Type", "application/json; charset=utf-8")
+		if req.URL.Query().Get("probe") == "live" {
+			json.NewEncoder(res).Encode(&JSONLiveness{Uptime: time.Since(processStarted).Seconds()})
+			return
+		}
+
+		h := NewJSONHealth(master)
+		if !h.Healthy {
+			res.WriteHeader(http.StatusServiceUnavailable)
+		}
+		json.NewEncoder(res).Encode(h)
+	})
+}
+
+// ServeHealth starts a standalone HTTP listener on addr, serving the health
+// of master at /health, see NewHealthHandler. The returned server can be
+// shut down with its Close method.
+func ServeHealth(addr string, master *Master) (server *http.Server, err error) {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/health", NewHealthHandler(master))
+
+	server = &http.Server{Addr: listener.Addr().String(), Handler: mux}
+	go server.Serve(listener)
+	return server, nil
+}
@@ -0,0 +1,133 @@
+package gobot
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"gobot.io/x/gobot/gobottest"
+)
+
+type testHealthDriver struct {
+	testDriver
+	state HealthState
+	err   error
+}
+
+func (t *testHealthDriver) Health() (HealthState, error) { return t.state, t.err }
+
+func TestHealthState(t *testing.T) {
+	gobottest.Assert(t, HealthOK.String(), "ok")
+	gobottest.Assert(t, HealthWarning.String(), "warning")
+	gobottest.Assert(t, HealthFault.String(), "fault")
+	gobottest.Assert(t, HealthDisconnected.String(), "disconnected")
+	gobottest.Assert(t, HealthState(9).String(), "HealthState(9)")
+}
+
+func TestHealthHandlerNotRunning(t *testing.T) {
+	g := initTestMaster1Robot()
+
+	res := httptest.NewRecorder()
+	NewHealthHandler(g).ServeHTTP(res, httptest.NewRequest("GET", "/health", nil))
+	gobottest.Assert(t, res.Code, http.StatusServiceUnavailable)
+
+	var h JSONHealth
+	json.NewDecoder(res.Body).Decode(&h)
+	gobottest.Assert(t, h.Healthy, false)
+	gobottest.Assert(t, h.Uptime > 0, true)
+	gobottest.Assert(t, h.Robots[0].Name, "Robot99")
+	gobottest.Assert(t, h.Robots[0].Running, false)
+	gobottest.Assert(t, h.Counters["robots"], 1)
+	gobottest.Assert(t, h.Counters["devices"], 3)
+	gobottest.Assert(t, h.Counters["connections"], 3)
+}
+
+func TestHealthHandlerRunning(t *testing.T) {
+	g := initTestMaster1Robot()
+	r := g.Robot("Robot99")
+	gobottest.Assert(t, r.Start(false), nil)
+	defer r.Stop()
+
+	res := httptest.NewRecorder()
+	NewHealthHandler(g).ServeHTTP(res, httptest.NewRequest("GET", "/health", nil))
+	gobottest.Assert(t, res.Code, http.StatusOK)
+}
+
+func TestHealthHandlerUnhealthyDevice(t *testing.T) {
+	g := initTestMaster1Robot()
+	r := g.Robot("Robot99")
+	r.AddDevice(&testHealthDriver{
+		testDriver: testDriver{name: "sick", Commander: NewCommander()},
+		state:      HealthDisconnected,
+		err:        errors.New("sensor disconnected"),
+	})
+	gobottest.Assert(t, r.Start(false), nil)
+	defer r.Stop()
+
+	res := httptest.NewRecorder()
+	NewHealthHandler(g).ServeHTTP(res, httptest.NewRequest("GET", "/health", nil))
+	gobottest.Assert(t, res.Code, http.StatusServiceUnavailable)
+
+	var h JSONHealth
+	json.NewDecoder(res.Body).Decode(&h)
+	gobottest.Assert(t, h.Counters["unhealthy_devices"], 1)
+	sick := h.Robots[0].Devices[len(h.Robots[0].Devices)-1]
+	gobottest.Assert(t, sick.Healthy, false)
+	gobottest.Assert(t, sick.State, "disconnected")
+	gobottest.Assert(t, sick.Error, "sensor disconnected")
+}
+
+func TestHealthHandlerWarningDevice(t *testing.T) {
+	g := initTestMaster1Robot()
+	r := g.Robot("Robot99")
+	r.AddDevice(&testHealthDriver{
+		testDriver: testDriver{name: "drifting", Commander: NewCommander()},
+		state:      HealthWarning,
+		err:        errors.New("calibration due"),
+	})
+	gobottest.Assert(t, r.Start(false), nil)
+	defer r.Stop()
+
+	// a warning does not make the robot unready
+	res := httptest.NewRecorder()
+	NewHealthHandler(g).ServeHTTP(res, httptest.NewRequest("GET", "/health", nil))
+	gobottest.Assert(t, res.Code, http.StatusOK)
+
+	var h JSONHealth
+	json.NewDecoder(res.Body).Decode(&h)
+	gobottest.Assert(t, h.Counters["unhealthy_devices"], 0)
+	drifting := h.Robots[0].Devices[len(h.Robots[0].Devices)-1]
+	gobottest.Assert(t, drifting.Healthy, true)
+	gobottest.Assert(t, drifting.State, "warning")
+	gobottest.Assert(t, drifting.Error, "calibration due")
+}
+
+func TestHealthHandlerLiveness(t *testing.T) {
+	// the process is live while the robot is not ready
+	res := httptest.NewRecorder()
+	NewHealthHandler(initTestMaster1Robot()).ServeHTTP(res, httptest.NewRequest("GET", "/health?probe=live", nil))
+	gobottest.Assert(t, res.Code, http.StatusOK)
+
+	var l JSONLiveness
+	json.NewDecoder(res.Body).Decode(&l)
+	gobottest.Assert(t, l.Uptime > 0, true)
+}
+
+func TestServeHealth(t *testing.T) {
+	server, err := ServeHealth("127.0.0.1:0", initTestMaster1Robot())
+	gobottest.Assert(t, err, nil)
+	defer server.Close()
+
+	res, err := http.Get("http://" + server.Addr + "/health")
+	gobottest.Assert(t, err, nil)
+	res.Body.Close()
+	gobottest.Assert(t, res.StatusCode, http.StatusServiceUnavailable)
+
+	// the process is live while the robot is not ready
+	res, err = http.Get("http://" + server.Addr + "/health?probe=live")
+	gobottest.Assert(t, err, nil)
+	res.Body.Close()
+	gobottest.Assert(t, res.StatusCode, http.StatusOK)
+}
@@ -0,0 +1,8 @@
+/*
+Package usbiss provides the Gobot adaptor for the Devantech USB-ISS
+serial to I2C bridge.
+
+For more information refer to the README:
+https://github.com/hybridgroup/gobot/blob/master/platforms/usbiss/README.md
+*/
+package usbiss // import "gobot.io/x/gobot/platforms/usbiss"
@@ -0,0 +1,189 @@
+package usbiss
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+
+	serial "go.bug.st/serial.v1"
+	"gobot.io/x/gobot"
+	"gobot.io/x/gobot/drivers/i2c"
+)
+
+const (
+	issCommand = 0x5A
+	issVersion = 0x01
+	issMode    = 0x02
+
+	// issDigitalInputs sets the I/O type of the unused pins I/O1 and I/O2
+	// to digital input, 2 bits per pin (00 output low, 01 output high,
+	// 10 digital input, 11 analog input).
+	issDigitalInputs = 0xAA
+
+	i2cSingle = 0x53
+	i2cAD0    = 0x54
+	i2cAD1    = 0x55
+	i2cTest   = 0x58
+
+	issAck = 0xFF
+
+	// issModuleID is the module ID reported by every USB-ISS
+	issModuleID = 0x07
+
+	// maxTransfer is the largest number of data bytes in a single I2C command
+	maxTransfer = 60
+)
+
+const (
+	// I2CSoftware20kHz selects software I2C at 20 kHz
+	I2CSoftware20kHz = 0x20
+	// I2CSoftware50kHz selects software I2C at 50 kHz
+	I2CSoftware50kHz = 0x30
+	// I2CSoftware100kHz selects software I2C at 100 kHz
+	I2CSoftware100kHz = 0x40
+	// I2CSoftware400kHz selects software I2C at 400 kHz
+	I2CSoftware400kHz = 0x50
+	// I2CHardware100kHz selects hardware I2C at 100 kHz
+	I2CHardware100kHz = 0x60
+	// I2CHardware400kHz selects hardware I2C at 400 kHz
+	I2CHardware400kHz = 0x70
+	// I2CHardware1000kHz selects hardware I2C at 1 MHz
+	I2CHardware1000kHz = 0x80
+)
+
+var (
+	// ErrNotUSBISS is returned by Connect when the port is not a USB-ISS
+	ErrNotUSBISS = errors.New("Device is not a USB-ISS")
+	// ErrI2CNack is returned when the addressed device does not acknowledge a write
+	ErrI2CNack = errors.New("I2C device did not acknowledge")
+	// ErrTransferSize is returned for transfers larger than a single USB-ISS command allows
+	ErrTransferSize = errors.New("USB-ISS transfers are limited to 60 bytes")
+)
+
+// Adaptor is the Gobot Adaptor for the Devantech USB-ISS serial to I2C
+// bridge. It implements the i2c.Connector interface, so the i2c drivers can
+// be used from any desktop or gateway with a free USB port.
+type Adaptor struct {
+	name       string
+	port       string
+	mode       byte
+	conn       io.ReadWriteCloser
+	mutex      *sync.Mutex
+	PortOpener func(port string) (io.ReadWriteCloser, error)
+}
+
+// NewAdaptor returns a new USB-ISS Adaptor which optionally accepts:
+//
+//	string: port the Adaptor uses to connect to the USB-ISS
+//	io.ReadWriteCloser: connection the Adaptor uses to communicate with the USB-ISS
+//
+// The I2C bus runs at 100 kHz using the hardware I2C unit unless changed
+// with SetI2CMode before Connect.
+func NewAdaptor(args ...interface{}) *Adaptor {
+	a := &Adaptor{
+		name:  gobot.DefaultName("USB-ISS"),
+		mode:  I2CHardware100kHz,
+		mutex: &sync.Mutex{},
+		PortOpener: func(port string) (io.ReadWriteCloser, error) {
+			return serial.Open(port, &serial.Mode{BaudRate: 19200})
+		},
+	}
+
+	for _, arg := range args {
+		switch arg.(type) {
+		case string:
+			a.port = arg.(string)
+		case io.ReadWriteCloser:
+			a.conn = arg.(io.ReadWriteCloser)
+		}
+	}
+
+	return a
+}
+
+// Name returns the Adaptors name
+func (a *Adaptor) Name() string { return a.name }
+
+// SetName sets the Adaptors name
+func (a *Adaptor) SetName(n string) { a.name = n }
+
+// Port returns the Adaptors port
+func (a *Adaptor) Port() string { return a.port }
+
+// SetI2CMode selects the I2C speed and implementation using one of the
+// I2CSoftware or I2CHardware constants. It must be called before Connect.
+func (a *Adaptor) SetI2CMode(mode byte) { a.mode = mode }
+
+// Connect opens the serial port, verifies the module is a USB-ISS and
+// switches it to I2C mode.
+func (a *Adaptor) Connect() (err error) {
+	if a.conn == nil {
+		if a.conn, err = a.PortOpener(a.Port()); err != nil {
+			return err
+		}
+	}
+
+	version := make([]byte, 3)
+	if err = a.transfer([]byte{issCommand, issVersion}, version); err != nil {
+		return err
+	}
+	if version[0] != issModuleID {
+		return ErrNotUSBISS
+	}
+
+	// the last byte leaves the I/O pins not used by I2C as digital inputs,
+	// rather than driving them low
+	ack := make([]byte, 2)
+	if err = a.transfer([]byte{issCommand, issMode, a.mode, issDigitalInputs}, ack); err != nil {
+		return err
+	}
+	if ack[0] != issAck {
+		return fmt.Errorf("USB-ISS rejected I2C mode 0x%02X with error 0x%02X", a.mode, ack[1])
+	}
+	return
+}
+
+// Finalize closes the serial port.
+func (a *Adaptor) Finalize() (err error) {
+	if a.conn != nil {
+		err = a.conn.Close()
+		a.conn = nil
+	}
+	return
+}
+
+// GetConnection returns a connection to the i2c device at address. The
+// USB-ISS has a single bus, so bus is ignored.
+func (a *Adaptor) GetConnection(address int, bus int) (connection i2c.Connection, err error) {
+	return NewI2cConnection(a, address), nil
+}
+
+// GetDefaultBus returns the default i2c bus of the USB-ISS.
+func (a *Adaptor) GetDefaultBus() int {
+	return 0
+}
+
+// Probe returns true if a device acknowledges the given address.
+func (a *Adaptor) Probe(address int) (found bool, err error) {
+	res := []byte{0}
+	if err = a.transfer([]byte{i2cTest, byte(address << 1)}, res); err != nil {
+		return false, err
+	}
+	return res[0] != 0, nil
+}
+
+// transfer writes a command to the USB-ISS and reads the complete response.
+func (a *Adaptor) transfer(cmd []byte, res []byte) (err error) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	if a.conn == nil {
+		return errors.New("USB-ISS is not connected")
+	}
+	if _, err = a.conn.Write(cmd); err != nil {
+		return err
+	}
+	_, err = io.ReadFull(a.conn, res)
+	return
+}
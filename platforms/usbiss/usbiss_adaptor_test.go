@@ -0,0 +1,97 @@
+package usbiss
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"gobot.io/x/gobot"
+	"gobot.io/x/gobot/drivers/i2c"
+	"gobot.io/x/gobot/gobottest"
+)
+
+// make sure that this Adaptor fullfills all the required interfaces
+var _ gobot.Adaptor = (*Adaptor)(nil)
+var _ i2c.Connector = (*Adaptor)(nil)
+
+type readWriteCloser struct {
+	read    *bytes.Buffer
+	written *bytes.Buffer
+	closed  bool
+}
+
+func (r *readWriteCloser) Write(p []byte) (int, error) { return r.written.Write(p) }
+func (r *readWriteCloser) Read(b []byte) (int, error)  { return r.read.Read(b) }
+func (r *readWriteCloser) Close() error {
+	r.closed = true
+	return nil
+}
+
+func newReadWriteCloser(response ...byte) *readWriteCloser {
+	return &readWriteCloser{read: bytes.NewBuffer(response), written: &bytes.Buffer{}}
+}
+
+func initTestAdaptor() (*Adaptor, *readWriteCloser) {
+	rwc := newReadWriteCloser(issModuleID, 0x07, 0x40, issAck, 0x00)
+	a := NewAdaptor("/dev/null", rwc)
+	a.Connect()
+	rwc.written.Reset()
+	return a, rwc
+}
+
+func TestUSBISSAdaptor(t *testing.T) {
+	a := NewAdaptor("/dev/ttyACM0")
+	gobottest.Assert(t, a.Port(), "/dev/ttyACM0")
+	gobottest.Assert(t, strings.HasPrefix(a.Name(), "USB-ISS"), true)
+	a.SetName("bridge")
+	gobottest.Assert(t, a.Name(), "bridge")
+	gobottest.Assert(t, a.GetDefaultBus(), 0)
+}
+
+func TestUSBISSAdaptorConnect(t *testing.T) {
+	rwc := newReadWriteCloser(issModuleID, 0x07, 0x40, issAck, 0x00)
+	a := NewAdaptor(rwc)
+	a.SetI2CMode(I2CHardware400kHz)
+	gobottest.Assert(t, a.Connect(), nil)
+	gobottest.Assert(t, rwc.written.Bytes(), []byte{0x5A, 0x01, 0x5A, 0x02, 0x70, 0xAA})
+}
+
+func TestUSBISSAdaptorConnectPortError(t *testing.T) {
+	a := NewAdaptor("/dev/null")
+	a.PortOpener = func(port string) (io.ReadWriteCloser, error) {
+		return nil, errors.New("connect error")
+	}
+	gobottest.Assert(t, a.Connect(), errors.New("connect error"))
+}
+
+func TestUSBISSAdaptorConnectNotUSBISS(t *testing.T) {
+	a := NewAdaptor(newReadWriteCloser(0x01, 0x02, 0x03))
+	gobottest.Assert(t, a.Connect(), ErrNotUSBISS)
+}
+
+func TestUSBISSAdaptorConnectModeRejected(t *testing.T) {
+	a := NewAdaptor(newReadWriteCloser(issModuleID, 0x07, 0x40, 0x00, 0x05))
+	gobottest.Assert(t, a.Connect(), errors.New("USB-ISS rejected I2C mode 0x60 with error 0x05"))
+}
+
+func TestUSBISSAdaptorFinalize(t *testing.T) {
+	a, rwc := initTestAdaptor()
+	gobottest.Assert(t, a.Finalize(), nil)
+	gobottest.Assert(t, rwc.closed, true)
+
+	_, err := a.Probe(0x40)
+	gobottest.Assert(t, err, errors.New("USB-ISS is not connected"))
+}
+
+func TestUSBISSAdaptorProbe(t *testing.T) {
+	a, rwc := initTestAdaptor()
+	rwc.read.Write([]byte{0x01, 0x00})
+
+	found, _ := a.Probe(0x40)
+	gobottest.Assert(t, found, true)
+	found, _ = a.Probe(0x41)
+	gobottest.Assert(t, found, false)
+	gobottest.Assert(t, rwc.written.Bytes(), []byte{0x58, 0x80, 0x58, 0x82})
+}
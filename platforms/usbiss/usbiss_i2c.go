@@ -0,0 +1,109 @@
+package usbiss
+
+import "gobot.io/x/gobot/drivers/i2c"
+
+type usbissI2cConnection struct {
+	address byte
+	adaptor *Adaptor
+}
+
+// NewI2cConnection creates an I2C connection to an I2C device at
+// the specified address
+func NewI2cConnection(adaptor *Adaptor, address int) (connection *usbissI2cConnection) {
+	return &usbissI2cConnection{adaptor: adaptor, address: byte(address)}
+}
+
+func (c *usbissI2cConnection) readAddress() byte  { return c.address<<1 | 0x01 }
+func (c *usbissI2cConnection) writeAddress() byte { return c.address << 1 }
+
+// write sends a write command and checks the acknowledge byte returned
+// by the USB-ISS.
+func (c *usbissI2cConnection) write(cmd []byte) (err error) {
+	ack := []byte{0}
+	if err = c.adaptor.transfer(cmd, ack); err != nil {
+		return
+	}
+	if ack[0] == 0 {
+		return ErrI2CNack
+	}
+	return
+}
+
+// Read reads a full buffer from the i2c device.
+func (c *usbissI2cConnection) Read(b []byte) (read int, err error) {
+	if len(b) > maxTransfer {
+		return 0, ErrTransferSize
+	}
+	if err = c.adaptor.transfer([]byte{i2cAD0, c.readAddress(), byte(len(b))}, b); err != nil {
+		return 0, err
+	}
+	return len(b), nil
+}
+
+// Write writes a buffer to the i2c device.
+func (c *usbissI2cConnection) Write(data []byte) (written int, err error) {
+	if len(data) > maxTransfer {
+		return 0, ErrTransferSize
+	}
+	if err = c.write(append([]byte{i2cAD0, c.writeAddress(), byte(len(data))}, data...)); err != nil {
+		return 0, err
+	}
+	return len(data), nil
+}
+
+// Close the connection. The serial port is closed when the adaptor is finalized.
+func (c *usbissI2cConnection) Close() error {
+	return nil
+}
+
+// ReadByte reads a single byte from the i2c device.
+func (c *usbissI2cConnection) ReadByte() (val byte, err error) {
+	buf := []byte{0}
+	err = c.adaptor.transfer([]byte{i2cSingle, c.readAddress()}, buf)
+	return buf[0], err
+}
+
+// ReadByteData reads a byte value for a register on the i2c device.
+func (c *usbissI2cConnection) ReadByteData(reg uint8) (val uint8, err error) {
+	buf := []byte{0}
+	err = c.adaptor.transfer([]byte{i2cAD1, c.readAddress(), reg, 1}, buf)
+	return buf[0], err
+}
+
+// ReadWordData reads a word value for a register on the i2c device.
+func (c *usbissI2cConnection) ReadWordData(reg uint8) (val uint16, err error) {
+	buf := []byte{0, 0}
+	if err = c.adaptor.transfer([]byte{i2cAD1, c.readAddress(), reg, 2}, buf); err != nil {
+		return
+	}
+	return uint16(buf[1])<<8 | uint16(buf[0]), nil
+}
+
+// WriteByte writes a single byte to the i2c device.
+func (c *usbissI2cConnection) WriteByte(val byte) (err error) {
+	return c.write([]byte{i2cSingle, c.writeAddress(), val})
+}
+
+// WriteByteData writes a byte value to a register on the i2c device.
+func (c *usbissI2cConnection) WriteByteData(reg uint8, val uint8) (err error) {
+	return c.write([]byte{i2cAD1, c.writeAddress(), reg, 1, val})
+}
+
+// WriteWordData writes a word value to a register on the i2c device.
+func (c *usbissI2cConnection) WriteWordData(reg uint8, val uint16) (err error) {
+	return c.write([]byte{i2cAD1, c.writeAddress(), reg, 2, byte(val), byte(val >> 8)})
+}
+
+// WriteBlockData writes a block of bytes to a register on the i2c device.
+func (c *usbissI2cConnection) WriteBlockData(reg uint8, b []byte) (err error) {
+	if len(b) > maxTransfer {
+		return ErrTransferSize
+	}
+	return c.write(append([]byte{i2cAD1, c.writeAddress(), reg, byte(len(b))}, b...))
+}
+
+// Capabilities returns the operations supported by the USB-ISS, every one
+// but SMBus packet error checking.
+func (c *usbissI2cConnection) Capabilities() i2c.Capability {
+	return i2c.AllCapabilities &^ i2c.CapabilityPEC
+}
@@ -0,0 +1,85 @@
+package usbiss
+
+import (
+	"testing"
+
+	"gobot.io/x/gobot/drivers/i2c"
+	"gobot.io/x/gobot/gobottest"
+)
+
+var _ i2c.Connection = (*usbissI2cConnection)(nil)
+
+func initTestI2cConnection() (i2c.Connection, *readWriteCloser) {
+	a, rwc := initTestAdaptor()
+	c, _ := a.GetConnection(0x40, a.GetDefaultBus())
+	return c, rwc
+}
+
+func TestUSBISSI2cReadWordData(t *testing.T) {
+	c, rwc := initTestI2cConnection()
+	rwc.read.Write([]byte{0x34, 0x12})
+
+	val, err := c.ReadWordData(0x07)
+	gobottest.Assert(t, err, nil)
+	gobottest.Assert(t, val, uint16(0x1234))
+	gobottest.Assert(t, rwc.written.Bytes(), []byte{0x55, 0x81, 0x07, 0x02})
+}
+
+func TestUSBISSI2cReadByteData(t *testing.T) {
+	c, rwc := initTestI2cConnection()
+	rwc.read.Write([]byte{0xAB})
+
+	val, _ := c.ReadByteData(0x02)
+	gobottest.Assert(t, val, uint8(0xAB))
+	gobottest.Assert(t, rwc.written.Bytes(), []byte{0x55, 0x81, 0x02, 0x01})
+}
+
+func TestUSBISSI2cReadByte(t *testing.T) {
+	c, rwc := initTestI2cConnection()
+	rwc.read.Write([]byte{0xAB})
+
+	val, _ := c.ReadByte()
+	gobottest.Assert(t, val, uint8(0xAB))
+	gobottest.Assert(t, rwc.written.Bytes(), []byte{0x53, 0x81})
+}
+
+func TestUSBISSI2cRead(t *testing.T) {
+	c, rwc := initTestI2cConnection()
+	rwc.read.Write([]byte{0x01, 0x02, 0x03})
+
+	buf := make([]byte, 3)
+	n, _ := c.Read(buf)
+	gobottest.Assert(t, n, 3)
+	gobottest.Assert(t, buf, []byte{0x01, 0x02, 0x03})
+	gobottest.Assert(t, rwc.written.Bytes(), []byte{0x54, 0x81, 0x03})
+
+	_, err := c.Read(make([]byte, 61))
+	gobottest.Assert(t, err, ErrTransferSize)
+}
+
+func TestUSBISSI2cWrite(t *testing.T) {
+	c, rwc := initTestI2cConnection()
+	rwc.read.Write([]byte{0x01})
+
+	n, err := c.Write([]byte{0x01, 0x02})
+	gobottest.Assert(t, err, nil)
+	gobottest.Assert(t, n, 2)
+	gobottest.Assert(t, rwc.written.Bytes(), []byte{0x54, 0x80, 0x02, 0x01, 0x02})
+}
+
+func TestUSBISSI2cWriteWordData(t *testing.T) {
+	c, rwc := initTestI2cConnection()
+	rwc.read.Write([]byte{0x01})
+
+	gobottest.Assert(t, c.WriteWordData(0x02, 0x1234), nil)
+	gobottest.Assert(t, rwc.written.Bytes(), []byte{0x55, 0x80, 0x02, 0x02, 0x34, 0x12})
+}
+
+func TestUSBISSI2cWriteNack(t *testing.T) {
+	c, rwc := initTestI2cConnection()
+	rwc.read.Write([]byte{0x00, 0x00, 0x00})
+
+	gobottest.Assert(t, c.WriteByte(0x01), ErrI2CNack)
+	gobottest.Assert(t, c.WriteByteData(0x01, 0x02), ErrI2CNack)
+	gobottest.Assert(t, c.WriteBlockData(0x01, []byte{0x02, 0x03}), ErrI2CNack)
+}
@@ -49,6 +49,7 @@ type Robot struct {
 	Work               func()
 	connections        *Connections
 	devices            *Devices
+	components         *Components
 	trap               func(chan os.Signal)
 	AutoRun            bool
 	running            atomic.Value
@@ -113,6 +114,7 @@ func NewRobot(v ...interface{}) *Robot {
 		Name:        fmt.Sprintf("%X", Rand(int(^uint(0)>>1))),
 		connections: &Connections{},
 		devices:     &Devices{},
+		components:  NewComponents(DefaultComponents),
 		done:        make(chan bool, 1),
 		trap: func(c chan os.Signal) {
 			signal.Notify(c, os.Interrupt)
@@ -162,6 +164,11 @@ func (r *Robot) Start(args ...interface{}) (err error) {
 		r.AutoRun = args[0].(bool)
 	}
 	log.Println("Starting Robot", r.Name, "...")
+	if uerr := r.useComponents(); uerr != nil {
+		err = multierror.Append(err, uerr)
+		log.Println(err)
+		return
+	}
 	if cerr := r.Connections().Start(); cerr != nil {
 		err = multierror.Append(err, cerr)
 		log.Println(err)
@@ -215,6 +222,33 @@ func (r *Robot) Stop() error {
 	return result
 }
 
+// Components returns the registry of components shared by the Robot's
+// connections and devices. Names not registered on the Robot are looked up
+// in DefaultComponents.
+func (r *Robot) Components() *Components {
+	return r.components
+}
+
+// useComponents hands the Robot's components to every connection and
+// device implementing ComponentUser.
+func (r *Robot) useComponents() (err error) {
+	r.Connections().Each(func(c Connection) {
+		if u, ok := c.(ComponentUser); ok {
+			if uerr := u.UseComponents(r.components); uerr != nil {
+				err = multierror.Append(err, uerr)
+			}
+		}
+	})
+	r.Devices().Each(func(d Device) {
+		if u, ok := d.(ComponentUser); ok {
+			if uerr := u.UseComponents(r.components); uerr != nil {
+				err = multierror.Append(err, uerr)
+			}
+		}
+	})
+	return
+}
+
 // Running returns if the Robot is currently started or not
 func (r *Robot) Running() bool {
 	return r.running.Load().(bool)
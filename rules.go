@@ -0,0 +1,341 @@
+package gobot
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	// RuleTriggered event, published with the name of the triggered rule
+	RuleTriggered = "ruletriggered"
+
+	// RuleError event, published when an action of a rule fails
+	RuleError = "ruleerror"
+)
+
+// webhookTimeout bounds the time a WebhookAction waits for the server.
+const webhookTimeout = 10 * time.Second
+
+var webhookClient = &http.Client{Timeout: webhookTimeout}
+
+// Facts holds the values a RulesEngine evaluates its rules against, by name.
+type Facts map[string]interface{}
+
+// Bool returns the fact name as bool. Missing and non-bool facts are false.
+func (f Facts) Bool(name string) bool {
+	b, _ := f[name].(bool)
+	return b
+}
+
+// Float returns the fact name as float64, converting any numeric type.
+// ok is false when the fact is missing or not numeric.
+func (f Facts) Float(name string) (val float64, ok bool) {
+	switch v := f[name].(type) {
+	case float64:
+		return v, true
+	case float32:
+		return float64(v), true
+	case int:
+		return float64(v), true
+	case int8:
+		return float64(v), true
+	case int16:
+		return float64(v), true
+	case int32:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case uint:
+		return float64(v), true
+	case uint8:
+		return float64(v), true
+	case uint16:
+		return float64(v), true
+	case uint32:
+		return float64(v), true
+	case uint64:
+		return float64(v), true
+	}
+	return 0, false
+}
+
+// Action is a single step executed when a rule is triggered. ctx is
+// cancelled when the run of the rule is cancelled, long running actions
+// should return early then.
+type Action func(ctx context.Context) error
+
+// Rule triggers its actions, one after the other, whenever its condition
+// becomes true. The condition has to become false again before the rule
+// can trigger a second time. Triggering a rule again cancels its previous
+// run, which finishes before the new run starts.
+type Rule struct {
+	Name      string
+	Condition func(f Facts) bool
+	Actions   []Action
+}
+
+type ruleState struct {
+	Rule
+	active bool
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// RulesEngine runs simple automations inside a robot. Facts are fed by
+// events of devices, or set directly, and every change re-evaluates the
+// rules. A fan switched on for five minutes on presence above 30°C reads:
+//
+//		rules := gobot.NewRulesEngine()
+//		rules.Watch("presence", presenceSensor, "presence")
+//		rules.Watch("temp", thermometer, "data")
+//		err := rules.AddRule(gobot.Rule{
+//			Name: "cool down",
+//			Condition: func(f gobot.Facts) bool {
+//				t, ok := f.Float("temp")
+//				return f.Bool("presence") && ok && t > 30
+//			},
+//			Actions: []gobot.Action{
+//				gobot.CommandAction(fan, "On", nil),
+//				gobot.DelayAction(5 * time.Minute),
+//				gobot.CommandAction(fan, "Off", nil),
+//			},
+//		})
+//
+// Halt stops watching the devices and cancels the running actions, call it
+// when the robot is stopped.
+//
+// Emits the Events:
+//	RuleTriggered string - name of the rule whose condition became true
+//	RuleError error - an action of a rule failed
+type RulesEngine struct {
+	mutex   *sync.Mutex
+	facts   Facts
+	rules   []*ruleState
+	watches []chan bool
+	Eventer
+}
+
+// NewRulesEngine returns a new RulesEngine without facts and rules.
+func NewRulesEngine() *RulesEngine {
+	e := &RulesEngine{
+		mutex:   &sync.Mutex{},
+		facts:   Facts{},
+		Eventer: NewEventer(),
+	}
+
+	e.AddEvent(RuleTriggered)
+	e.AddEvent(RuleError)
+
+	return e
+}
+
+// Watch sets the fact with the given name to the data of every event
+// published by source under the name event, until the engine is halted.
+func (e *RulesEngine) Watch(fact string, source Eventer, event string) error {
+	out := source.Subscribe()
+	halt := make(chan bool)
+
+	e.mutex.Lock()
+	e.watches = append(e.watches, halt)
+	e.mutex.Unlock()
+
+	go func() {
+		for {
+			select {
+			case evt := <-out:
+				if evt.Name == event {
+					e.Set(fact, evt.Data)
+				}
+			case <-halt:
+				UnsubscribeAndDrain(source, out)
+				return
+			}
+		}
+	}()
+	return nil
+}
+
+// Halt stops all watches and cancels the running actions of all rules. It
+// returns once the cancelled runs have finished.
+func (e *RulesEngine) Halt() error {
+	e.mutex.Lock()
+	watches := e.watches
+	e.watches = nil
+	var runs []chan struct{}
+	for _, r := range e.rules {
+		if r.cancel != nil {
+			r.cancel()
+			runs = append(runs, r.done)
+			r.cancel, r.done = nil, nil
+		}
+	}
+	e.mutex.Unlock()
+
+	for _, halt := range watches {
+		close(halt)
+	}
+	for _, done := range runs {
+		<-done
+	}
+	return nil
+}
+
+// Set sets a fact and evaluates all rules.
+func (e *RulesEngine) Set(fact string, value interface{}) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	e.facts[fact] = value
+	e.evaluate()
+}
+
+// Facts returns a copy of the current facts.
+func (e *RulesEngine) Facts() Facts {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	return e.copyFacts()
+}
+
+// AddRule adds a rule and evaluates it against the current facts. Rules
+// without a condition are rejected.
+func (e *RulesEngine) AddRule(r Rule) error {
+	if r.Condition == nil {
+		return fmt.Errorf("rule %s has no condition", r.Name)
+	}
+
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	e.rules = append(e.rules, &ruleState{Rule: r})
+	e.evaluate()
+	return nil
+}
+
+func (e *RulesEngine) copyFacts() Facts {
+	facts := Facts{}
+	for name, value := range e.facts {
+		facts[name] = value
+	}
+	return facts
+}
+
+// evaluate triggers every rule whose condition became true, it must be
+// called with the mutex held.
+func (e *RulesEngine) evaluate() {
+	facts := e.copyFacts()
+	for _, r := range e.rules {
+		matches := r.Condition(facts)
+		if matches && !r.active {
+			e.start(r)
+		}
+		r.active = matches
+	}
+}
+
+// start cancels the previous run of r and starts a new one, it must be
+// called with the mutex held.
+func (e *RulesEngine) start(r *ruleState) {
+	if r.cancel != nil {
+		r.cancel()
+	}
+	previous := r.done
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	r.cancel, r.done = cancel, done
+
+	go func() {
+		defer close(done)
+		defer cancel()
+		if previous != nil {
+			<-previous
+		}
+		e.run(ctx, r.Rule)
+	}()
+}
+
+func (e *RulesEngine) run(ctx context.Context, r Rule) {
+	e.Publish(RuleTriggered, r.Name)
+	for _, action := range r.Actions {
+		if ctx.Err() != nil {
+			return
+		}
+		if err := action(ctx); err != nil && ctx.Err() == nil {
+			e.Publish(RuleError, fmt.Errorf("rule %s: %v", r.Name, err))
+			return
+		}
+	}
+}
+
+// CommandAction returns an Action running the command name of c. Commands
+// returning an error, or a map with a non-nil "err" entry, fail the action.
+func CommandAction(c Commander, name string, params map[string]interface{}) Action {
+	return func(ctx context.Context) error {
+		command := c.Command(name)
+		if command == nil {
+			return fmt.Errorf("unknown command %s", name)
+		}
+
+		return commandError(command(params))
+	}
+}
+
+// PublishAction returns an Action publishing the event name with data on e.
+func PublishAction(e Eventer, name string, data interface{}) Action {
+	return func(ctx context.Context) error {
+		e.Publish(name, data)
+		return nil
+	}
+}
+
+// DelayAction returns an Action waiting for d before the next action runs,
+// or until the run is cancelled.
+func DelayAction(d time.Duration) Action {
+	return func(ctx context.Context) error {
+		timer := time.NewTimer(d)
+		defer timer.Stop()
+
+		select {
+		case <-timer.C:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// WebhookAction returns an Action posting payload as JSON to url. Responses
+// with a status other than 2xx, and servers not responding within 10
+// seconds, fail the action.
+func WebhookAction(url string, payload interface{}) Action {
+	return func(ctx context.Context) error {
+		body, err := json.Marshal(payload)
+		if err != nil {
+			return err
+		}
+
+		req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Content-Type", "application/json")
+
+		res, err := webhookClient.Do(req.WithContext(ctx))
+		if err != nil {
+			return err
+		}
+		res.Body.Close()
+
+		if res.StatusCode < 200 || res.StatusCode > 299 {
+			return errors.New("webhook responded with " + res.Status)
+		}
+		return nil
+	}
+}
@@ -0,0 +1,285 @@
+package gobot
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"gobot.io/x/gobot/gobottest"
+)
+
+func TestFacts(t *testing.T) {
+	f := Facts{"on": true, "int": 3, "uint8": uint8(4), "float": 1.5, "text": "x"}
+
+	gobottest.Assert(t, f.Bool("on"), true)
+	gobottest.Assert(t, f.Bool("text"), false)
+	gobottest.Assert(t, f.Bool("missing"), false)
+
+	v, ok := f.Float("int")
+	gobottest.Assert(t, v, 3.0)
+	gobottest.Assert(t, ok, true)
+	v, _ = f.Float("uint8")
+	gobottest.Assert(t, v, 4.0)
+	v, _ = f.Float("float")
+	gobottest.Assert(t, v, 1.5)
+	_, ok = f.Float("text")
+	gobottest.Assert(t, ok, false)
+}
+
+func TestRulesEngineTriggersOnRisingEdge(t *testing.T) {
+	e := NewRulesEngine()
+	triggered := make(chan interface{}, 10)
+	e.On(RuleTriggered, func(data interface{}) {
+		triggered <- data
+	})
+	err := e.AddRule(Rule{
+		Name: "hot",
+		Condition: func(f Facts) bool {
+			t, ok := f.Float("temp")
+			return ok && t > 30
+		},
+	})
+	gobottest.Assert(t, err, nil)
+
+	e.Set("temp", 25)
+	e.Set("temp", 31)
+	e.Set("temp", 32)
+	e.Set("temp", 20)
+	e.Set("temp", 35)
+
+	for i := 0; i < 2; i++ {
+		select {
+		case name := <-triggered:
+			gobottest.Assert(t, name, "hot")
+		case <-time.After(time.Second):
+			t.Fatalf("rule did not trigger")
+		}
+	}
+	select {
+	case <-triggered:
+		t.Errorf("rule triggered too often")
+	case <-time.After(10 * time.Millisecond):
+	}
+}
+
+func TestRulesEngineWatch(t *testing.T) {
+	source := NewEventer()
+	source.AddEvent("presence")
+
+	e := NewRulesEngine()
+	gobottest.Assert(t, e.Watch("presence", source, "presence"), nil)
+	e.AddRule(Rule{
+		Name:      "occupied",
+		Condition: func(f Facts) bool { return f.Bool("presence") },
+	})
+
+	sem := make(chan interface{}, 1)
+	e.Once(RuleTriggered, func(data interface{}) {
+		sem <- data
+	})
+
+	source.Publish("presence", true)
+
+	select {
+	case name := <-sem:
+		gobottest.Assert(t, name, "occupied")
+		gobottest.Assert(t, e.Facts().Bool("presence"), true)
+	case <-time.After(time.Second):
+		t.Errorf("RuleTriggered was not published")
+	}
+}
+
+func TestRulesEngineActionError(t *testing.T) {
+	e := NewRulesEngine()
+	sem := make(chan interface{}, 1)
+	e.Once(RuleError, func(data interface{}) {
+		sem <- data
+	})
+
+	second := false
+	e.AddRule(Rule{
+		Name:      "always",
+		Condition: func(f Facts) bool { return true },
+		Actions: []Action{
+			func(context.Context) error { return errors.New("broken fan") },
+			func(context.Context) error { second = true; return nil },
+		},
+	})
+
+	select {
+	case err := <-sem:
+		gobottest.Assert(t, err, errors.New("rule always: broken fan"))
+		gobottest.Assert(t, second, false)
+	case <-time.After(time.Second):
+		t.Errorf("RuleError was not published")
+	}
+}
+
+func TestRulesEngineAddRuleWithoutCondition(t *testing.T) {
+	e := NewRulesEngine()
+	gobottest.Assert(t, e.AddRule(Rule{Name: "broken"}), errors.New("rule broken has no condition"))
+	e.Set("temp", 31)
+}
+
+func TestRulesEngineCancelsPreviousRun(t *testing.T) {
+	e := NewRulesEngine()
+	started := make(chan int, 2)
+	finished := make(chan int, 2)
+	run := 0
+	e.AddRule(Rule{
+		Name:      "on",
+		Condition: func(f Facts) bool { return f.Bool("on") },
+		Actions: []Action{
+			func(context.Context) error {
+				run++
+				started <- run
+				return nil
+			},
+			DelayAction(time.Hour),
+			func(context.Context) error {
+				finished <- run
+				return nil
+			},
+		},
+	})
+
+	e.Set("on", true)
+	gobottest.Assert(t, <-started, 1)
+	e.Set("on", false)
+	e.Set("on", true)
+
+	// the first run is cancelled before the second one starts
+	select {
+	case n := <-started:
+		gobottest.Assert(t, n, 2)
+	case <-time.After(time.Second):
+		t.Fatalf("rule did not run again")
+	}
+	gobottest.Assert(t, e.Halt(), nil)
+	gobottest.Assert(t, len(finished), 0)
+}
+
+func TestRulesEngineHalt(t *testing.T) {
+	source := NewEventer()
+	source.AddEvent("presence")
+
+	e := NewRulesEngine()
+	e.Watch("presence", source, "presence")
+	cancelled := make(chan error, 1)
+	e.AddRule(Rule{
+		Name:      "occupied",
+		Condition: func(f Facts) bool { return f.Bool("presence") },
+		Actions: []Action{func(ctx context.Context) error {
+			<-ctx.Done()
+			cancelled <- ctx.Err()
+			return nil
+		}},
+	})
+
+	sem := make(chan bool, 1)
+	e.Once(RuleTriggered, func(data interface{}) {
+		sem <- true
+	})
+	source.Publish("presence", true)
+	select {
+	case <-sem:
+	case <-time.After(time.Second):
+		t.Fatalf("RuleTriggered was not published")
+	}
+
+	gobottest.Assert(t, e.Halt(), nil)
+	gobottest.Assert(t, <-cancelled, context.Canceled)
+
+	// events are not watched anymore, and a halted watch does not block
+	// the source
+	for i := 0; i < 3*eventChanBufferSize; i++ {
+		source.Publish("presence", false)
+	}
+	time.Sleep(10 * time.Millisecond)
+	gobottest.Assert(t, e.Facts().Bool("presence"), true)
+}
+
+func TestCommandAction(t *testing.T) {
+	c := NewCommander()
+	called := false
+	c.AddCommand("On", func(params map[string]interface{}) interface{} {
+		called = params["level"] == 1
+		return nil
+	})
+	c.AddCommand("Read", func(params map[string]interface{}) interface{} {
+		return map[string]interface{}{"val": 0, "err": errors.New("read error")}
+	})
+	c.AddCommand("Fail", func(params map[string]interface{}) interface{} {
+		return errors.New("fail")
+	})
+
+	gobottest.Assert(t, CommandAction(c, "On", map[string]interface{}{"level": 1})(context.Background()), nil)
+	gobottest.Assert(t, called, true)
+	gobottest.Assert(t, CommandAction(c, "Read", nil)(context.Background()), errors.New("read error"))
+	gobottest.Assert(t, CommandAction(c, "Fail", nil)(context.Background()), errors.New("fail"))
+	gobottest.Assert(t, CommandAction(c, "Missing", nil)(context.Background()), errors.New("unknown command Missing"))
+}
+
+func TestPublishAction(t *testing.T) {
+	e := NewEventer()
+	e.AddEvent("alarm")
+	sem := make(chan interface{}, 1)
+	e.Once("alarm", func(data interface{}) {
+		sem <- data
+	})
+
+	gobottest.Assert(t, PublishAction(e, "alarm", 42)(context.Background()), nil)
+	select {
+	case data := <-sem:
+		gobottest.Assert(t, data, 42)
+	case <-time.After(time.Second):
+		t.Errorf("event was not published")
+	}
+}
+
+func TestDelayAction(t *testing.T) {
+	start := time.Now()
+	gobottest.Assert(t, DelayAction(10*time.Millisecond)(context.Background()), nil)
+	gobottest.Assert(t, time.Since(start) >= 10*time.Millisecond, true)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	gobottest.Assert(t, DelayAction(time.Hour)(ctx), context.Canceled)
+}
+
+func TestWebhookAction(t *testing.T) {
+	var received map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		json.NewDecoder(req.Body).Decode(&received)
+		if received["fail"] == true {
+			res.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer server.Close()
+
+	gobottest.Assert(t, WebhookAction(server.URL, map[string]interface{}{"rule": "hot"})(context.Background()), nil)
+	gobottest.Assert(t, received["rule"], "hot")
+
+	err := WebhookAction(server.URL, map[string]interface{}{"fail": true})(context.Background())
+	gobottest.Assert(t, strings.Contains(err.Error(), "500"), true)
+}
+
+func TestWebhookActionCancel(t *testing.T) {
+	release := make(chan bool)
+	server := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		<-release
+	}))
+	defer server.Close()
+	defer close(release)
+
+	gobottest.Assert(t, webhookClient.Timeout, 10*time.Second)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	gobottest.Refute(t, WebhookAction(server.URL, nil)(ctx), nil)
+}
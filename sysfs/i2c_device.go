@@ -3,6 +3,7 @@ package sysfs
 import (
 	"fmt"
 	"os"
+	"strings"
 	"syscall"
 	"unsafe"
 )
@@ -19,6 +20,8 @@ const (
 
 	// From  /usr/include/linux/i2c.h:
 	// Adapter functionality
+	I2C_FUNC_I2C                    = 0x00000001
+	I2C_FUNC_SMBUS_PEC              = 0x00000008
 	I2C_FUNC_SMBUS_READ_BYTE        = 0x00020000
 	I2C_FUNC_SMBUS_WRITE_BYTE       = 0x00040000
 	I2C_FUNC_SMBUS_READ_BYTE_DATA   = 0x00080000
@@ -46,14 +49,15 @@ type i2cSmbusIoctlData struct {
 }
 
 type i2cDevice struct {
-	file  File
-	funcs uint64 // adapter functionality mask
+	location string
+	file     File
+	funcs    uint64 // adapter functionality mask
 }
 
 // NewI2cDevice returns an io.ReadWriteCloser with the proper ioctrl given
 // an i2c bus location.
 func NewI2cDevice(location string) (d *i2cDevice, err error) {
-	d = &i2cDevice{}
+	d = &i2cDevice{location: location}
 
 	if d.file, err = OpenFile(location, os.O_RDWR, os.ModeExclusive); err != nil {
 		return
@@ -65,6 +69,21 @@ func NewI2cDevice(location string) (d *i2cDevice, err error) {
 	return
 }
 
+// CheckI2cBus returns an error with a remediation hint when the i2c bus
+// device file at location is missing or not accessible.
+func CheckI2cBus(location string) error {
+	f, err := OpenFile(location, os.O_RDWR, 0)
+	switch {
+	case os.IsNotExist(err):
+		return fmt.Errorf("missing, enable I2C for the platform and load the i2c-dev module")
+	case os.IsPermission(err):
+		return fmt.Errorf("permission denied, add the user to the group owning %s", location)
+	case err != nil:
+		return err
+	}
+	return f.Close()
+}
+
 func (d *i2cDevice) queryFunctionality() (err error) {
 	_, _, errno := Syscall(
 		syscall.SYS_IOCTL,
@@ -79,6 +98,11 @@ func (d *i2cDevice) queryFunctionality() (err error) {
 	return
 }
 
+// Functionality returns the functionality mask reported by the adapter.
+func (d *i2cDevice) Functionality() uint64 {
+	return d.funcs
+}
+
 func (d *i2cDevice) SetAddress(address int) (err error) {
 	_, _, errno := Syscall(
 		syscall.SYS_IOCTL,
@@ -87,6 +111,9 @@ func (d *i2cDevice) SetAddress(address int) (err error) {
 		uintptr(byte(address)),
 	)
 
+	if errno == syscall.EBUSY {
+		return d.addressBusyError(address)
+	}
 	if errno != 0 {
 		err = fmt.Errorf("Setting address failed with syscall.Errno %v", errno)
 	}
@@ -94,6 +121,28 @@ func (d *i2cDevice) SetAddress(address int) (err error) {
 	return
 }
 
+// addressBusyError returns the error for an address claimed by a kernel
+// driver, naming the device and how to release it.
+func (d *i2cDevice) addressBusyError(address int) error {
+	var bus int
+	if _, err := fmt.Sscanf(d.location, "/dev/i2c-%d", &bus); err != nil {
+		return fmt.Errorf("Setting address failed, address 0x%02X is in use by a kernel driver", address)
+	}
+
+	device := fmt.Sprintf("%d-%04x", bus, address)
+	name := "a kernel driver"
+	if f, err := OpenFile("/sys/bus/i2c/devices/"+device+"/name", os.O_RDONLY, 0644); err == nil {
+		buf := make([]byte, 32)
+		if n, _ := f.Read(buf); n > 0 {
+			name = "the kernel driver of " + strings.TrimSpace(string(buf[:n]))
+		}
+		f.Close()
+	}
+	return fmt.Errorf("Setting address failed, address 0x%02X is in use by %s; "+
+		"release it with 'echo %s > /sys/bus/i2c/devices/%s/driver/unbind' or read the sensor via its hwmon interface",
+		address, name, device, device)
+}
+
 func (d *i2cDevice) Close() (err error) {
 	return d.file.Close()
 }
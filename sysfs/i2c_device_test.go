@@ -3,6 +3,7 @@ package sysfs
 import (
 	"errors"
 	"os"
+	"strings"
 	"syscall"
 	"testing"
 
@@ -83,6 +84,33 @@ func TestNewI2cDevice(t *testing.T) {
 	gobottest.Assert(t, err, nil)
 }
 
+func TestI2cDeviceSetAddressBusy(t *testing.T) {
+	fs := NewMockFilesystem([]string{
+		"/dev/i2c-1",
+		"/sys/bus/i2c/devices/1-0048/name",
+	})
+	fs.Files["/sys/bus/i2c/devices/1-0048/name"].Contents = "tmp007\n"
+	SetFilesystem(fs)
+
+	SetSyscall(&MockSyscall{
+		Impl: func(trap, a1, a2, a3 uintptr) (r1, r2 uintptr, err syscall.Errno) {
+			if a2 == I2C_SLAVE {
+				return 0, 0, syscall.EBUSY
+			}
+			return 0, 0, 0
+		},
+	})
+
+	i, _ := NewI2cDevice("/dev/i2c-1")
+	gobottest.Assert(t, i.SetAddress(0x48), errors.New("Setting address failed, address 0x48 is in use by the kernel driver of tmp007; "+
+		"release it with 'echo 1-0048 > /sys/bus/i2c/devices/1-0048/driver/unbind' or read the sensor via its hwmon interface"))
+
+	err := i.SetAddress(0x49)
+	gobottest.Assert(t, strings.Contains(err.Error(), "address 0x49 is in use by a kernel driver; release it with 'echo 1-0049"), true)
+
+	SetSyscall(&MockSyscall{})
+}
+
 func TestNewI2cDeviceReadByte(t *testing.T) {
 	fs := NewMockFilesystem([]string{
 		"/dev/i2c-1",
@@ -347,3 +375,34 @@ func TestNewI2cDeviceWrite(t *testing.T) {
 	gobottest.Assert(t, n, len(buf))
 	gobottest.Assert(t, err, nil)
 }
+
+// checkI2cBusFilesystem is a mock filesystem failing to open the files in
+// errs with the given error.
+type checkI2cBusFilesystem struct {
+	*MockFilesystem
+	errs map[string]error
+}
+
+func (fs *checkI2cBusFilesystem) OpenFile(name string, flag int, perm os.FileMode) (File, error) {
+	if err, ok := fs.errs[name]; ok {
+		return nil, &os.PathError{Op: "open", Path: name, Err: err}
+	}
+	return fs.MockFilesystem.OpenFile(name, flag, perm)
+}
+
+func TestCheckI2cBus(t *testing.T) {
+	SetFilesystem(&checkI2cBusFilesystem{
+		MockFilesystem: NewMockFilesystem([]string{"/dev/i2c-1"}),
+		errs: map[string]error{
+			"/dev/i2c-2": os.ErrNotExist,
+			"/dev/i2c-3": os.ErrPermission,
+		},
+	})
+	defer SetFilesystem(&NativeFilesystem{})
+
+	gobottest.Assert(t, CheckI2cBus("/dev/i2c-1"), nil)
+	gobottest.Assert(t, CheckI2cBus("/dev/i2c-2"),
+		errors.New("missing, enable I2C for the platform and load the i2c-dev module"))
+	gobottest.Assert(t, CheckI2cBus("/dev/i2c-3"),
+		errors.New("permission denied, add the user to the group owning /dev/i2c-3"))
+}